@@ -0,0 +1,170 @@
+// breachbuild, HIBP "Pwned Passwords" biçimindeki (SHA1HEX:COUNT) ya da düz
+// metin şifre listelerinden auth servisinin kullandığı sızıntı veri setini üretir.
+//
+//	go run ./cmd/breachbuild -in pwned-passwords-sha1-ordered-by-hash.txt -out breached.bin -min-count 10
+//
+// HIBP'nin hash'e göre sıralı dosyası bellek kullanmadan akış halinde işlenir;
+// sırasız girdiler için -sort bayrağı tüm kayıtları bellekte sıralar.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"auth-service/pkg/security"
+)
+
+func main() {
+	in := flag.String("in", "-", "girdi dosyası (- ise stdin)")
+	out := flag.String("out", "breached.bin", "çıktı dosyası")
+	minCount := flag.Int("min-count", 0, "HIBP sayacı bu değerin altındaki kayıtları atla")
+	plain := flag.Bool("plain", false, "girdi hash değil düz metin şifre listesi")
+	sortInput := flag.Bool("sort", false, "girdiyi bellekte sırala (sıralı olmayan girdiler için)")
+	flag.Parse()
+
+	reader := os.Stdin
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("girdi açılamadı: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	output, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("çıktı oluşturulamadı: %v", err)
+	}
+	writer := bufio.NewWriterSize(output, 1<<20)
+
+	records := make(chan []byte, 1024)
+	parseErr := make(chan error, 1)
+	go func() {
+		parseErr <- parse(reader, *plain, *minCount, records)
+		close(records)
+	}()
+
+	var written int
+	if *plain || *sortInput {
+		written, err = writeSorted(writer, records)
+	} else {
+		written, err = writeStream(writer, records)
+	}
+	if err == nil {
+		err = <-parseErr
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		log.Fatalf("veri seti oluşturulamadı: %v", err)
+	}
+
+	fmt.Printf("%d kayıt %s dosyasına yazıldı\n", written, *out)
+}
+
+// parse girdiyi satır satır okuyup SHA-1 özetlerini kanala yazar
+func parse(reader io.Reader, plain bool, minCount int, records chan<- []byte) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if plain {
+			digest := sha1.Sum([]byte(text))
+			records <- digest[:]
+			continue
+		}
+
+		hash, count, hasCount := strings.Cut(text, ":")
+		if hasCount && minCount > 0 {
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				return fmt.Errorf("satır %d: geçersiz sayaç %q", line, count)
+			}
+			if n < minCount {
+				continue
+			}
+		}
+
+		digest, err := hex.DecodeString(hash)
+		if err != nil || len(digest) != security.BreachRecordSize {
+			return fmt.Errorf("satır %d: geçersiz SHA-1 özeti %q", line, hash)
+		}
+		records <- digest
+	}
+	return scanner.Err()
+}
+
+// writeStream sıralı girdiyi tekrar edenleri atlayarak doğrudan yazar
+func writeStream(writer io.Writer, records <-chan []byte) (int, error) {
+	var previous []byte
+	written := 0
+	for record := range records {
+		switch cmp := bytes.Compare(record, previous); {
+		case previous != nil && cmp == 0:
+			continue
+		case previous != nil && cmp < 0:
+			drain(records)
+			return written, fmt.Errorf("girdi sıralı değil, -sort bayrağını kullanın")
+		}
+
+		if _, err := writer.Write(record); err != nil {
+			drain(records)
+			return written, err
+		}
+		previous = record
+		written++
+	}
+	return written, nil
+}
+
+// writeSorted tüm kayıtları bellekte sıralayıp tekrar edenleri atlayarak yazar
+func writeSorted(writer io.Writer, records <-chan []byte) (int, error) {
+	var all [][]byte
+	for record := range records {
+		all = append(all, record)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return bytes.Compare(all[i], all[j]) < 0
+	})
+
+	written := 0
+	for i, record := range all {
+		if i > 0 && bytes.Equal(record, all[i-1]) {
+			continue
+		}
+		if _, err := writer.Write(record); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// drain üreticinin kanalda bloklanmaması için kalan kayıtları tüketir
+func drain(records <-chan []byte) {
+	for range records {
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"time"
 
 	"auth-service/internal/config"
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/domain/oauth"
+	"auth-service/internal/domain/validator"
 	"auth-service/internal/handlers"
 	"auth-service/internal/infrastructure/cache"
 	"auth-service/internal/infrastructure/database"
@@ -26,8 +29,14 @@ func main() {
 		log.Fatalf("Config yüklenemedi: %v", err)
 	}
 
+	// Hassas kolonlar için anahtar şifreleme anahtarları
+	envelope, err := security.LoadEnvelope(cfg.Encryption.Keys, cfg.Encryption.KeysFile, cfg.Encryption.KeyVersion)
+	if err != nil {
+		log.Fatalf("Şifreleme anahtarları yüklenemedi: %v", err)
+	}
+
 	// Database bağlantısı
-	db, err := database.NewPostgresConnection(cfg.Database)
+	db, err := database.NewPostgresConnection(cfg.Database, envelope)
 	if err != nil {
 		log.Fatalf("Veritabanı bağlantısı kurulamadı: %v", err)
 	}
@@ -41,20 +50,51 @@ func main() {
 	defer redisClient.Close()
 
 	// JWT manager
-	jwtManager := security.NewJWTManager(security.JWTConfig{
+	jwtManager, err := security.NewJWTManager(security.JWTConfig{
 		AccessTokenSecret:  cfg.JWT.AccessTokenSecret,
 		RefreshTokenSecret: cfg.JWT.RefreshTokenSecret,
 		AccessTokenTTL:     cfg.JWT.AccessTokenTTL,
 		RefreshTokenTTL:    cfg.JWT.RefreshTokenTTL,
+		MFATokenTTL:        cfg.JWT.MFATokenTTL,
+		MFATokenSecret:     cfg.JWT.MFATokenSecret,
 		Issuer:             cfg.JWT.Issuer,
+		Audience:           cfg.JWT.Audience,
+		SigningAlgorithm:   cfg.JWT.SigningAlgorithm,
+		PrivateKeyPath:     cfg.JWT.PrivateKeyPath,
+	})
+	if err != nil {
+		log.Fatalf("JWT yapılandırması geçersiz: %v", err)
+	}
+
+	// Şifre hash'leyici
+	passwordHasher, err := security.NewPasswordHasher(security.PasswordHashConfig{
+		Algorithm: cfg.Password.HashAlgorithm,
+		Argon2: security.Argon2Params{
+			Memory:      cfg.Password.Argon2Memory,
+			Iterations:  cfg.Password.Argon2Iterations,
+			Parallelism: cfg.Password.Argon2Parallelism,
+		},
+		BcryptCost: cfg.Password.BcryptCost,
 	})
+	if err != nil {
+		log.Fatalf("Şifre hash yapılandırması geçersiz: %v", err)
+	}
+
+	// Sızıntı veri seti (opsiyonel)
+	var breachCorpus *security.BreachCorpus
+	if cfg.Password.BreachCorpusPath != "" {
+		breachCorpus, err = security.OpenBreachCorpus(cfg.Password.BreachCorpusPath)
+		if err != nil {
+			log.Fatalf("Sızıntı veri seti yüklenemedi: %v", err)
+		}
+		defer breachCorpus.Close()
+	}
 
 	// OAuth2 providers
-	googleProvider := oauth.NewGoogleProvider(
-		cfg.OAuth.GoogleClientID,
-		cfg.OAuth.GoogleClientSecret,
-		cfg.OAuth.GoogleRedirectURL,
-	)
+	oauthProviders, err := buildOAuthProviders(cfg.OAuth)
+	if err != nil {
+		log.Fatalf("Sosyal giriş sağlayıcıları yüklenemedi: %v", err)
+	}
 
 	// Email service
 	emailService := service.NewEmailService(
@@ -71,8 +111,18 @@ func main() {
 	// Repositories
 	userRepo := repository.NewGormUserRepository(db.GetDB())
 	sessionRepo := repository.NewSessionRepository(redisClient.GetClient())
+	tokenRepo := repository.NewTokenRepository(redisClient.GetClient(), cfg.JWT.AccessTokenTTL)
 	auditRepo := repository.NewAuditRepository(db.GetDB())
 	securityRepo := repository.NewSecurityRepository(db.GetDB())
+	recoveryRepo := repository.NewRecoveryCodeRepository(db.GetDB())
+	signingKeyRepo := repository.NewSigningKeyRepository(db.GetDB())
+	oauthClientRepo := repository.NewOAuthClientRepository(db.GetDB())
+	passwordHistoryRepo := repository.NewPasswordHistoryRepository(db.GetDB())
+	userTokenRepo := repository.NewUserTokenRepository(db.GetDB())
+	oauthStateRepo := repository.NewOAuthStateRepository(redisClient.GetClient())
+	oauthLinkRepo := repository.NewOAuthLinkRepository(redisClient.GetClient())
+	identityRepo := repository.NewUserIdentityRepository(db.GetDB())
+	authorizationCodeRepo := repository.NewAuthorizationCodeRepository(redisClient.GetClient())
 
 	// Services
 	monitoringService := service.NewMonitoringService(auditRepo, securityRepo)
@@ -80,18 +130,83 @@ func main() {
 		userRepo,
 		securityRepo,
 		sessionRepo,
+		tokenRepo,
 		monitoringService,
+		service.LockoutPolicy{
+			Threshold:    cfg.Auth.LockoutThreshold,
+			LockDuration: cfg.Auth.LockoutDuration,
+			BaseDelay:    cfg.Auth.LoginBackoffBase,
+			MaxDelay:     cfg.Auth.LoginBackoffMax,
+		},
+	)
+
+	// İmza anahtarları: başlangıçta veritabanından yüklenir, periyodik olarak yenilenir
+	keyService := service.NewKeyService(
+		signingKeyRepo,
+		jwtManager,
+		securityService,
+		cfg.JWT.KeyPublishDelay,
+	)
+	if err := keyService.Load(context.Background()); err != nil {
+		log.Fatalf("İmza anahtarları yüklenemedi: %v", err)
+	}
+	keyService.StartReload(context.Background(), cfg.JWT.KeyReloadInterval)
+
+	oauthService := service.NewOAuthService(
+		oauthClientRepo,
+		authorizationCodeRepo,
+		userRepo,
+		sessionRepo,
+		auditRepo,
+		jwtManager,
+		securityService,
+		service.AuthorizationServerConfig{
+			CodeTTL:  cfg.OAuth.AuthorizationCodeTTL,
+			LoginURL: cfg.OAuth.AuthorizeLoginURL,
+		},
 	)
 
 	authService := service.NewAuthService(
 		userRepo,
 		jwtManager,
+		passwordHasher,
 		emailService,
 		totpService,
 		sessionRepo,
 		auditRepo,
+		recoveryRepo,
+		passwordHistoryRepo,
+		userTokenRepo,
+		oauthStateRepo,
+		oauthLinkRepo,
+		identityRepo,
+		breachCorpus,
 		securityService,
-		googleProvider,
+		oauthProviders,
+		service.AuthConfig{
+			EmailVerificationMode:  cfg.Auth.EmailVerificationMode,
+			EmailVerificationTTL:   cfg.Auth.EmailVerificationTTL,
+			PasswordResetTTL:       cfg.Auth.PasswordResetTTL,
+			OAuthStateTTL:          cfg.OAuth.StateTTL,
+			OAuthRedirectAllowlist: cfg.OAuth.RedirectAllowlist,
+			BreachCheckOnLogin:     cfg.Password.BreachCheckOnLogin,
+			PasswordHistorySize:    cfg.Password.HistorySize,
+			PasswordMaxAge:         cfg.Password.MaxAge,
+			PasswordMaxAgeByRole:   cfg.Password.MaxAgeByRole,
+			PasswordPolicy: validator.PasswordPolicy{
+				MinLength:           cfg.Password.Policy.MinLength,
+				MaxLength:           cfg.Password.Policy.MaxLength,
+				PassphraseMinLength: cfg.Password.Policy.PassphraseMinLength,
+				RequireUpper:        cfg.Password.Policy.RequireUpper,
+				RequireLower:        cfg.Password.Policy.RequireLower,
+				RequireDigit:        cfg.Password.Policy.RequireDigit,
+				RequireSymbol:       cfg.Password.Policy.RequireSymbol,
+				AllowSpaces:         cfg.Password.Policy.AllowSpaces,
+				MaxRepeated:         cfg.Password.Policy.MaxRepeated,
+				MinEntropyBits:      cfg.Password.Policy.MinEntropyBits,
+				BannedWords:         cfg.Password.Policy.BannedWords,
+			},
+		},
 	)
 
 	// Fiber app
@@ -109,11 +224,23 @@ func main() {
 
 	// Middleware'ler
 	app.Use(middleware.RateLimit(redisClient.GetClient(), 100, time.Minute))
+	app.Use(middleware.RequestMeta())
 	app.Use(middleware.RequestMetrics(monitoringService))
 	app.Use(recover.New())
 	app.Use(logger.New())
 
 	// Routes
+	// Diğer servislerin access token'ları yerelde doğrulaması için açık anahtarlar
+	app.Get("/.well-known/jwks.json", handlers.JWKS(jwtManager))
+
+	// Servis istemcileri için token introspection (RFC 7662) ve iptal (RFC 7009)
+	oauthRoutes := app.Group("/oauth")
+	oauthRoutes.Post("/introspect", handlers.Introspect(oauthService))
+	oauthRoutes.Post("/revoke", handlers.RevokeToken(oauthService))
+	// Web ve mobil uygulamalar için authorization code + PKCE akışı
+	oauthRoutes.Get("/authorize", handlers.Authorize(oauthService))
+	oauthRoutes.Post("/token", handlers.Token(oauthService))
+
 	api := app.Group("/api")
 	v1 := api.Group("/v1")
 
@@ -121,25 +248,55 @@ func main() {
 	auth := v1.Group("/auth")
 	auth.Post("/register", handlers.Register(authService))
 	auth.Post("/login", handlers.Login(authService))
+	auth.Post("/login/2fa", handlers.Login2FA(authService))
 	auth.Post("/refresh", handlers.RefreshToken(authService))
 	auth.Post("/forgot-password", handlers.ForgotPassword(authService))
 	auth.Post("/reset-password", handlers.ResetPassword(authService))
 	auth.Get("/verify-email", handlers.VerifyEmail(authService))
+	auth.Get("/password-policy", handlers.PasswordPolicy(authService))
+	auth.Post("/resend-verification",
+		middleware.RouteRateLimit(redisClient.GetClient(), "resend_verification", 3, time.Hour),
+		handlers.ResendVerificationEmail(authService),
+	)
 
 	// OAuth routes
-	auth.Get("/google/login", handlers.GoogleLogin(googleProvider))
-	auth.Get("/google/callback", handlers.GoogleCallback(authService))
+	auth.Get("/oauth/providers", handlers.OAuthProviders(authService))
+	auth.Get("/:provider/login", handlers.OAuthLogin(authService))
+	auth.Get("/:provider/callback", handlers.OAuthCallback(authService))
+	auth.Post("/:provider/callback", handlers.OAuthCallback(authService)) // form_post (Apple)
+	auth.Post("/oauth/link", handlers.LinkIdentity(authService))
 
 	// Protected routes
 	protected := v1.Group("/protected")
-	protected.Use(middleware.JWTAuth(jwtManager))
+	protected.Use(middleware.JWTAuth(jwtManager, securityService))
+	// Email doğrulanmamış kullanıcılar (limited mod) yalnızca kendi hesap işlemlerine erişir
+	protected.Use(middleware.RestrictScope(entity.ScopeUnverified, "/api/v1/protected/user"))
+	// Şifresi süresi dolmuş veya işaretlenmiş kullanıcılar yeni şifre belirleyene kadar
+	protected.Use(middleware.RestrictScope(entity.ScopePasswordChange, "/api/v1/protected/user/change-password"))
 
 	// User routes
 	user := protected.Group("/user")
 	user.Post("/change-password", handlers.ChangePassword(authService))
 	user.Post("/2fa/enable", handlers.Enable2FA(authService))
 	user.Post("/2fa/verify", handlers.Verify2FA(authService))
+	user.Post("/2fa/disable", handlers.Disable2FA(authService))
+	user.Post("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes(authService))
 	user.Get("/audit-logs", handlers.GetAuditLogs(authService))
+	user.Post("/logout", handlers.Logout(authService))
+	user.Post("/logout-all", handlers.LogoutAll(authService))
+	user.Get("/sessions", handlers.GetSessions(authService))
+	user.Delete("/sessions/:sid", handlers.RevokeSession(authService))
+	user.Get("/identities", handlers.GetIdentities(authService))
+	user.Delete("/identities/:id", handlers.UnlinkIdentity(authService))
+
+	// Giriş arayüzünün, giriş yapan kullanıcı adına authorization code alması
+	protected.Post("/oauth/authorize", handlers.AuthorizeGrant(oauthService))
+
+	// Destek ve güvenlik ekibinin kullanıcı işlemleri (izin bazlı)
+	users := protected.Group("/users")
+	users.Post("/:id/2fa/reset", middleware.RequirePermission(entity.PermissionReset2FA), handlers.Reset2FA(authService))
+	users.Get("/:id/sessions", middleware.RequirePermission(entity.PermissionViewUserDetails), handlers.GetUserSessions(authService))
+	users.Delete("/:id/sessions/:sid", middleware.RequirePermission(entity.PermissionViewUserDetails), handlers.RevokeUserSession(authService))
 
 	// Security routes
 	security := protected.Group("/security")
@@ -161,6 +318,84 @@ func main() {
 	admin.Use(middleware.RequireRole(entity.RoleAdmin))
 	admin.Get("/users", handlers.ListUsers(authService))
 	admin.Post("/users/:id/role", handlers.ChangeUserRole(authService))
+	admin.Post("/users/require-password-change", handlers.RequirePasswordChange(authService))
+	admin.Get("/keys", handlers.ListSigningKeys(keyService))
+	admin.Post("/keys/rotate", handlers.RotateSigningKey(keyService))
+	admin.Get("/oauth/clients", handlers.ListOAuthClients(oauthService))
+	admin.Post("/oauth/clients", handlers.CreateOAuthClient(oauthService))
+	admin.Delete("/oauth/clients/:id", handlers.DeactivateOAuthClient(oauthService))
 
 	log.Fatal(app.Listen(cfg.Server.Address))
 }
+
+// buildOAuthProviders yapılandırmada etkin olan sosyal giriş sağlayıcılarını
+// kaydeder; OIDC tabanlı olanların discovery belgesi başlangıçta okunur
+func buildOAuthProviders(cfg config.OAuthConfig) (*oauth.Registry, error) {
+	registry := oauth.NewRegistry()
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if cfg.Google.Enabled {
+		provider, err := oauth.NewGoogleProvider(ctx, cfg.Google.ClientID, cfg.Google.ClientSecret, cfg.Google.RedirectURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.GitHub.Enabled {
+		provider := oauth.NewGitHubProvider(cfg.GitHub.ClientID, cfg.GitHub.ClientSecret, cfg.GitHub.RedirectURL, httpClient)
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Microsoft.Enabled {
+		provider, err := oauth.NewMicrosoftProvider(ctx, cfg.MicrosoftTenant, cfg.Microsoft.ClientID, cfg.Microsoft.ClientSecret, cfg.Microsoft.RedirectURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Apple.Enabled {
+		provider, err := oauth.NewAppleProvider(ctx, cfg.Apple.ClientID, cfg.AppleTeamID, cfg.AppleKeyID, cfg.ApplePrivateKeyPath, cfg.Apple.RedirectURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.GitLab.Enabled {
+		provider, err := oauth.NewGitLabProvider(ctx, cfg.GitLabURL, cfg.GitLab.ClientID, cfg.GitLab.ClientSecret, cfg.GitLab.RedirectURL, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, providerCfg := range cfg.OIDCProviders {
+		provider, err := oauth.NewOIDCProvider(ctx, oauth.OIDCConfig{
+			Name:         providerCfg.Name,
+			IssuerURL:    providerCfg.IssuerURL,
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  providerCfg.RedirectURL,
+			Scopes:       providerCfg.Scopes,
+			HTTPClient:   httpClient,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,125 @@
+// reencrypt, şifreli kolonlardaki değerleri geçerli anahtar şifreleme anahtarı
+// (KEK) ile yeniden şifreler. KEK rotasyonunda yeni sürüm ENCRYPTION_KEYS
+// listesine eklendikten sonra, eski sürüm listeden çıkarılmadan önce çalıştırılır;
+// şifrelenmemiş eski değerleri de şifreler.
+//
+//	go run ./cmd/reencrypt -dry-run
+//	go run ./cmd/reencrypt -batch 500
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"auth-service/internal/config"
+	"auth-service/internal/infrastructure/database"
+	"auth-service/pkg/security"
+
+	"gorm.io/gorm"
+)
+
+// encryptedColumn `serializer:encrypted` ile saklanan bir kolondur
+type encryptedColumn struct {
+	Table  string
+	Column string
+}
+
+// encryptedColumns yeni bir şifreli kolon eklendiğinde buraya da eklenmelidir
+var encryptedColumns = []encryptedColumn{
+	{Table: "users", Column: "totp_secret"},
+	{Table: "users", Column: "pending_totp_secret"},
+	{Table: "signing_keys", Column: "key_material"},
+}
+
+type row struct {
+	ID    string
+	Value string
+}
+
+func main() {
+	batchSize := flag.Int("batch", 500, "tek seferde okunan satır sayısı")
+	dryRun := flag.Bool("dry-run", false, "değişiklik yapmadan yeniden şifrelenecek satırları say")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Config yüklenemedi: %v", err)
+	}
+
+	envelope, err := security.LoadEnvelope(cfg.Encryption.Keys, cfg.Encryption.KeysFile, cfg.Encryption.KeyVersion)
+	if err != nil {
+		log.Fatalf("Şifreleme anahtarları yüklenemedi: %v", err)
+	}
+
+	db, err := database.NewPostgresConnection(cfg.Database, envelope)
+	if err != nil {
+		log.Fatalf("Veritabanı bağlantısı kurulamadı: %v", err)
+	}
+	defer db.Close()
+
+	for _, column := range encryptedColumns {
+		updated, err := reencryptColumn(db.GetDB(), envelope, column, *batchSize, *dryRun)
+		if err != nil {
+			log.Fatalf("%s.%s yeniden şifrelenemedi: %v", column.Table, column.Column, err)
+		}
+		if *dryRun {
+			fmt.Printf("%s.%s: %d değer sürüm %s ile yeniden şifrelenecek\n", column.Table, column.Column, updated, envelope.CurrentVersion())
+			continue
+		}
+		fmt.Printf("%s.%s: %d değer sürüm %s ile yeniden şifrelendi\n", column.Table, column.Column, updated, envelope.CurrentVersion())
+	}
+}
+
+// reencryptColumn kolonu id sırasıyla gezer ve düz metin ya da eski sürümle
+// şifrelenmiş değerleri geçerli sürümle yeniden yazar. Güncelleme eski değer
+// koşuluyla yapılır; arada değişen satırların üzerine yazılmaz.
+func reencryptColumn(db *gorm.DB, envelope *security.Envelope, column encryptedColumn, batchSize int, dryRun bool) (int, error) {
+	updated := 0
+	lastID := ""
+	for {
+		query := db.Table(column.Table).
+			Select("id, " + column.Column + " AS value").
+			Where(column.Column + " IS NOT NULL AND " + column.Column + " <> ''")
+		if lastID != "" {
+			query = query.Where("id > ?", lastID)
+		}
+
+		var rows []row
+		if err := query.Order("id").Limit(batchSize).Scan(&rows).Error; err != nil {
+			return updated, err
+		}
+		if len(rows) == 0 {
+			return updated, nil
+		}
+
+		for _, r := range rows {
+			lastID = r.ID
+			if !envelope.NeedsReencrypt(r.Value) {
+				continue
+			}
+			if dryRun {
+				updated++
+				continue
+			}
+
+			aad := security.AssociatedData(column.Table, column.Column, r.ID)
+			plaintext, err := envelope.Decrypt(r.Value, aad)
+			if err != nil {
+				return updated, fmt.Errorf("satır %s: %w", r.ID, err)
+			}
+			encrypted, err := envelope.Encrypt(plaintext, aad)
+			if err != nil {
+				return updated, err
+			}
+
+			result := db.Table(column.Table).
+				Where("id = ? AND "+column.Column+" = ?", r.ID, r.Value).
+				Update(column.Column, encrypted)
+			if result.Error != nil {
+				return updated, result.Error
+			}
+			updated += int(result.RowsAffected)
+		}
+	}
+}
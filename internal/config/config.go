@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"auth-service/internal/domain/entity"
+
 	"github.com/joho/godotenv"
 )
 
@@ -16,6 +19,10 @@ type Config struct {
 	JWT      JWTConfig
 	SMTP     SMTPConfig
 	OAuth    OAuthConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	// Encryption hassas kolonların (TOTP secret vb.) şifrelenmesinde kullanılan anahtarlar
+	Encryption EncryptionConfig
 }
 
 type ServerConfig struct {
@@ -42,7 +49,14 @@ type JWTConfig struct {
 	RefreshTokenSecret string
 	AccessTokenTTL     time.Duration
 	RefreshTokenTTL    time.Duration
+	MFATokenTTL        time.Duration
+	MFATokenSecret     string
 	Issuer             string
+	Audience           string
+	SigningAlgorithm   string
+	PrivateKeyPath     string
+	KeyPublishDelay    time.Duration
+	KeyReloadInterval  time.Duration
 }
 
 type SMTPConfig struct {
@@ -53,10 +67,100 @@ type SMTPConfig struct {
 	From     string
 }
 
+type AuthConfig struct {
+	EmailVerificationMode string // optional, required veya limited
+	EmailVerificationTTL  time.Duration
+	PasswordResetTTL      time.Duration
+	LockoutThreshold      int
+	LockoutDuration       time.Duration
+	LoginBackoffBase      time.Duration
+	LoginBackoffMax       time.Duration
+}
+
+// PasswordConfig şifre hash ayarları; sıfır değerler güvenli varsayılanlara döner
+type PasswordConfig struct {
+	HashAlgorithm     string // argon2id veya bcrypt
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+	// BreachCorpusPath cmd/breachbuild ile üretilen sızıntı veri seti; boşsa kontrol yapılmaz
+	BreachCorpusPath   string
+	BreachCheckOnLogin bool
+	// HistorySize yeniden kullanılamayacak önceki şifre sayısı
+	HistorySize int
+	// MaxAge şifrenin azami yaşı (0: süresiz); MaxAgeByRole rol bazında ezer
+	MaxAge       time.Duration
+	MaxAgeByRole map[entity.Role]time.Duration
+	Policy       PasswordPolicyConfig
+}
+
+// PasswordPolicyConfig yeni şifrelerin uyması gereken kurallar
+type PasswordPolicyConfig struct {
+	MinLength           int
+	MaxLength           int
+	PassphraseMinLength int // Bu uzunluktaki şifrelerden karakter sınıfı şartı aranmaz
+	RequireUpper        bool
+	RequireLower        bool
+	RequireDigit        bool
+	RequireSymbol       bool
+	AllowSpaces         bool
+	MaxRepeated         int
+	MinEntropyBits      float64
+	BannedWords         []string
+}
+
+// EncryptionConfig anahtar şifreleme anahtarları (KEK); "<sürüm>:<base64 32 bayt>"
+// girdileri virgül veya satır sonuyla ayrılır. KeysFile tanımlıysa Keys yok sayılır.
+type EncryptionConfig struct {
+	Keys       string
+	KeysFile   string
+	KeyVersion string // Yeni değerler için kullanılan sürüm; boşsa son girdi
+}
+
+// OAuthProviderConfig tek bir sosyal giriş sağlayıcısının ayarları; <AD>_ENABLED
+// verilmezse client id tanımlıysa sağlayıcı etkin sayılır
+type OAuthProviderConfig struct {
+	Enabled      bool
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
 type OAuthConfig struct {
-	GoogleClientID     string
-	GoogleClientSecret string
-	GoogleRedirectURL  string
+	Google    OAuthProviderConfig
+	GitHub    OAuthProviderConfig
+	Microsoft OAuthProviderConfig
+	Apple     OAuthProviderConfig
+	GitLab    OAuthProviderConfig
+	// MicrosoftTenant Entra kiracı kimliği; boşsa "common"
+	MicrosoftTenant string
+	// Apple client secret'ı bu anahtarla imzalanan JWT'dir (ClientSecret kullanılmaz)
+	AppleTeamID         string
+	AppleKeyID          string
+	ApplePrivateKeyPath string
+	// GitLabURL kendi sunucusunda çalışan GitLab için; boşsa gitlab.com
+	GitLabURL string
+	StateTTL  time.Duration
+	// RedirectAllowlist sosyal girişten sonra izin verilen istemci adresleri
+	RedirectAllowlist []string
+	// OIDCProviders discovery ile yapılandırılan ek OpenID Connect sağlayıcıları
+	OIDCProviders []OIDCProviderConfig
+	// AuthorizationCodeTTL servisin kendi /oauth/authorize ucunun verdiği code'ların süresi
+	AuthorizationCodeTTL time.Duration
+	// AuthorizeLoginURL /oauth/authorize isteklerinin yönlendirildiği giriş arayüzü
+	AuthorizeLoginURL string
+}
+
+// OIDCProviderConfig OIDC_PROVIDERS listesindeki her ad için OIDC_<AD>_ISSUER,
+// _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL ve _SCOPES değişkenlerinden okunur
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func Load() (*Config, error) {
@@ -77,6 +181,107 @@ func Load() (*Config, error) {
 	// JWT TTL'leri parse et
 	accessTTL, _ := time.ParseDuration(os.Getenv("JWT_ACCESS_TTL"))
 	refreshTTL, _ := time.ParseDuration(os.Getenv("JWT_REFRESH_TTL"))
+	mfaTTL, err := time.ParseDuration(os.Getenv("JWT_MFA_TTL"))
+	if err != nil {
+		mfaTTL = 5 * time.Minute
+	}
+
+	// Access token'ların aud değeri; ayrı tanımlanmadıysa issuer kullanılır
+	jwtAudience := os.Getenv("JWT_AUDIENCE")
+	if jwtAudience == "" {
+		jwtAudience = os.Getenv("JWT_ISSUER")
+	}
+
+	// İmza anahtarı rotasyonu ayarları
+	keyPublishDelay, err := time.ParseDuration(os.Getenv("JWT_KEY_PUBLISH_DELAY"))
+	if err != nil {
+		keyPublishDelay = 5 * time.Minute
+	}
+	keyReloadInterval, err := time.ParseDuration(os.Getenv("JWT_KEY_RELOAD_INTERVAL"))
+	if err != nil {
+		keyReloadInterval = time.Minute
+	}
+
+	// Email doğrulama ayarları
+	verificationTTL, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL"))
+	if err != nil {
+		verificationTTL = 24 * time.Hour
+	}
+	// Sosyal giriş ayarları
+	oauthStateTTL, err := time.ParseDuration(os.Getenv("OAUTH_STATE_TTL"))
+	if err != nil {
+		oauthStateTTL = 10 * time.Minute
+	}
+	var oauthRedirects []string
+	for _, redirect := range strings.Split(os.Getenv("OAUTH_REDIRECT_ALLOWLIST"), ",") {
+		if redirect = strings.TrimSpace(redirect); redirect != "" {
+			oauthRedirects = append(oauthRedirects, redirect)
+		}
+	}
+
+	authorizationCodeTTL, err := time.ParseDuration(os.Getenv("OAUTH_CODE_TTL"))
+	if err != nil {
+		authorizationCodeTTL = time.Minute
+	}
+	oidcProviders, err := loadOIDCProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	passwordResetTTL, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TTL"))
+	if err != nil {
+		passwordResetTTL = 30 * time.Minute
+	}
+	verificationMode := os.Getenv("EMAIL_VERIFICATION_MODE")
+	if verificationMode == "" {
+		verificationMode = "optional"
+	}
+
+	// Hesap kilitleme ayarları
+	lockoutThreshold, err := strconv.Atoi(os.Getenv("LOCKOUT_THRESHOLD"))
+	if err != nil {
+		lockoutThreshold = 5
+	}
+	lockoutDuration, err := time.ParseDuration(os.Getenv("LOCKOUT_DURATION"))
+	if err != nil {
+		lockoutDuration = 15 * time.Minute
+	}
+	backoffBase, err := time.ParseDuration(os.Getenv("LOGIN_BACKOFF_BASE"))
+	if err != nil {
+		backoffBase = time.Second
+	}
+	backoffMax, err := time.ParseDuration(os.Getenv("LOGIN_BACKOFF_MAX"))
+	if err != nil {
+		backoffMax = time.Minute
+	}
+
+	// Şifre hash ayarları
+	argonMemory, _ := strconv.ParseUint(os.Getenv("ARGON2_MEMORY"), 10, 32)
+	argonIterations, _ := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32)
+	argonParallelism, _ := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8)
+	bcryptCost, _ := strconv.Atoi(os.Getenv("BCRYPT_COST"))
+	breachCheckOnLogin, _ := strconv.ParseBool(os.Getenv("BREACH_CHECK_ON_LOGIN"))
+	minEntropy, _ := strconv.ParseFloat(os.Getenv("PASSWORD_MIN_ENTROPY_BITS"), 64)
+	passwordMaxAge, _ := time.ParseDuration(os.Getenv("PASSWORD_MAX_AGE"))
+	// rol=süre çiftleri, ör. "admin=720h,sec_admin=720h"
+	passwordMaxAgeByRole := map[entity.Role]time.Duration{}
+	for _, pair := range strings.Split(os.Getenv("PASSWORD_MAX_AGE_BY_ROLE"), ",") {
+		role, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		maxAge, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("geçersiz PASSWORD_MAX_AGE_BY_ROLE değeri %q: %w", pair, err)
+		}
+		passwordMaxAgeByRole[entity.Role(strings.TrimSpace(role))] = maxAge
+	}
+	var bannedWords []string
+	for _, word := range strings.Split(os.Getenv("PASSWORD_BANNED_WORDS"), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			bannedWords = append(bannedWords, word)
+		}
+	}
 
 	return &Config{
 		Server: ServerConfig{
@@ -100,7 +305,14 @@ func Load() (*Config, error) {
 			RefreshTokenSecret: os.Getenv("JWT_REFRESH_SECRET"),
 			AccessTokenTTL:     accessTTL,
 			RefreshTokenTTL:    refreshTTL,
+			MFATokenTTL:        mfaTTL,
+			MFATokenSecret:     os.Getenv("JWT_MFA_SECRET"),
 			Issuer:             os.Getenv("JWT_ISSUER"),
+			Audience:           jwtAudience,
+			SigningAlgorithm:   os.Getenv("JWT_SIGNING_ALG"),
+			PrivateKeyPath:     os.Getenv("JWT_PRIVATE_KEY_PATH"),
+			KeyPublishDelay:    keyPublishDelay,
+			KeyReloadInterval:  keyReloadInterval,
 		},
 		SMTP: SMTPConfig{
 			Host:     os.Getenv("SMTP_HOST"),
@@ -110,9 +322,119 @@ func Load() (*Config, error) {
 			From:     os.Getenv("SMTP_FROM"),
 		},
 		OAuth: OAuthConfig{
-			GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-			GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-			GoogleRedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
+			Google:               loadOAuthProvider("GOOGLE"),
+			GitHub:               loadOAuthProvider("GITHUB"),
+			Microsoft:            loadOAuthProvider("MICROSOFT"),
+			Apple:                loadOAuthProvider("APPLE"),
+			GitLab:               loadOAuthProvider("GITLAB"),
+			MicrosoftTenant:      os.Getenv("MICROSOFT_TENANT"),
+			AppleTeamID:          os.Getenv("APPLE_TEAM_ID"),
+			AppleKeyID:           os.Getenv("APPLE_KEY_ID"),
+			ApplePrivateKeyPath:  os.Getenv("APPLE_PRIVATE_KEY_PATH"),
+			GitLabURL:            os.Getenv("GITLAB_URL"),
+			StateTTL:             oauthStateTTL,
+			RedirectAllowlist:    oauthRedirects,
+			OIDCProviders:        oidcProviders,
+			AuthorizationCodeTTL: authorizationCodeTTL,
+			AuthorizeLoginURL:    os.Getenv("OAUTH_LOGIN_URL"),
+		},
+		Auth: AuthConfig{
+			EmailVerificationMode: verificationMode,
+			EmailVerificationTTL:  verificationTTL,
+			PasswordResetTTL:      passwordResetTTL,
+			LockoutThreshold:      lockoutThreshold,
+			LockoutDuration:       lockoutDuration,
+			LoginBackoffBase:      backoffBase,
+			LoginBackoffMax:       backoffMax,
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:      os.Getenv("PASSWORD_HASH_ALGORITHM"),
+			Argon2Memory:       uint32(argonMemory),
+			Argon2Iterations:   uint32(argonIterations),
+			Argon2Parallelism:  uint8(argonParallelism),
+			BcryptCost:         bcryptCost,
+			BreachCorpusPath:   os.Getenv("BREACH_CORPUS_PATH"),
+			BreachCheckOnLogin: breachCheckOnLogin,
+			HistorySize:        envInt("PASSWORD_HISTORY_SIZE", 5),
+			MaxAge:             passwordMaxAge,
+			MaxAgeByRole:       passwordMaxAgeByRole,
+			Policy: PasswordPolicyConfig{
+				MinLength:           envInt("PASSWORD_MIN_LENGTH", 8),
+				MaxLength:           envInt("PASSWORD_MAX_LENGTH", 128),
+				PassphraseMinLength: envInt("PASSWORD_PASSPHRASE_MIN_LENGTH", 20),
+				RequireUpper:        envBool("PASSWORD_REQUIRE_UPPER", true),
+				RequireLower:        envBool("PASSWORD_REQUIRE_LOWER", true),
+				RequireDigit:        envBool("PASSWORD_REQUIRE_DIGIT", true),
+				RequireSymbol:       envBool("PASSWORD_REQUIRE_SYMBOL", false),
+				AllowSpaces:         envBool("PASSWORD_ALLOW_SPACES", true),
+				MaxRepeated:         envInt("PASSWORD_MAX_REPEATED", 3),
+				MinEntropyBits:      minEntropy,
+				BannedWords:         bannedWords,
+			},
+		},
+		Encryption: EncryptionConfig{
+			Keys:       os.Getenv("ENCRYPTION_KEYS"),
+			KeysFile:   os.Getenv("ENCRYPTION_KEYS_FILE"),
+			KeyVersion: os.Getenv("ENCRYPTION_KEY_VERSION"),
 		},
 	}, nil
 }
+
+// envInt tamsayı ortam değişkenini okur; tanımsız veya geçersizse varsayılanı döner
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// envBool mantıksal ortam değişkenini okur; tanımsız veya geçersizse varsayılanı döner
+func envBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// loadOAuthProvider <prefix>_ENABLED, _CLIENT_ID, _CLIENT_SECRET ve
+// _REDIRECT_URL değişkenlerini okur
+func loadOAuthProvider(prefix string) OAuthProviderConfig {
+	clientID := os.Getenv(prefix + "_CLIENT_ID")
+	return OAuthProviderConfig{
+		Enabled:      envBool(prefix+"_ENABLED", clientID != ""),
+		ClientID:     clientID,
+		ClientSecret: os.Getenv(prefix + "_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv(prefix + "_REDIRECT_URL"),
+	}
+}
+
+// loadOIDCProviders OIDC_PROVIDERS içindeki her sağlayıcının ayarlarını okur;
+// "azure-ad" adı OIDC_AZURE_AD_* değişkenlerine karşılık gelir
+func loadOIDCProviders() ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("OIDC sağlayıcısı %q için %sISSUER ve %sCLIENT_ID gereklidir", name, prefix, prefix)
+		}
+		provider.Scopes = strings.FieldsFunc(os.Getenv(prefix+"SCOPES"), func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
@@ -12,9 +12,14 @@ const (
 	ActionLogin          AuditAction = "login"
 	ActionLogout         AuditAction = "logout"
 	ActionPasswordChange AuditAction = "password_change"
+	ActionPasswordReset  AuditAction = "password_reset"
 	ActionEmailVerify    AuditAction = "email_verify"
 	Action2FAEnable      AuditAction = "2fa_enable"
 	Action2FADisable     AuditAction = "2fa_disable"
+	ActionRecoveryCode   AuditAction = "2fa_recovery_code_use"
+	ActionIdentityLink   AuditAction = "identity_link"
+	ActionIdentityUnlink AuditAction = "identity_unlink"
+	ActionOAuthAuthorize AuditAction = "oauth_authorize"
 )
 
 type AuditLog struct {
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// OAuthClient servisin OAuth 2.0 istemcisidir: token introspection/iptal
+// uçlarını kullanan servisler ve kullanıcı adına token alan uygulamalar.
+// Client secret'ın kendisi değil SHA-256 özeti saklanır.
+type OAuthClient struct {
+	ID         string `gorm:"primarykey;type:varchar(64)"` // client_id
+	Name       string `gorm:"type:varchar(100);not null"`
+	SecretHash string `gorm:"type:varchar(64);not null" json:"-"`
+	// IsPublic secret saklayamayan (mobil, SPA) istemciler içindir; bu
+	// istemciler token ucunda yalnızca PKCE ile doğrulanır
+	IsPublic bool `gorm:"default:false"`
+	// RedirectURIs authorization code'un iletilebileceği adresler; birebir eşleşme aranır
+	RedirectURIs []string `gorm:"type:text;serializer:json;not null"`
+	// AllowedScopes istemcinin isteyebileceği kapsamlar
+	AllowedScopes []string `gorm:"type:text;serializer:json;not null"`
+	// IsResourceServer token'ları introspection ile doğrulayan API'ler
+	// içindir; diğer istemciler yalnızca kendilerine verilen token'ları görür
+	IsResourceServer bool   `gorm:"default:false"`
+	IsActive         bool   `gorm:"default:true"`
+	CreatedBy        string `gorm:"type:varchar(36)"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// AuthorizationCode /oauth/authorize ile verilen ve token ucunda bir kez
+// kullanılabilen code'un kaydıdır
+type AuthorizationCode struct {
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scope         string
+	CodeChallenge string // S256 PKCE challenge
+	CreatedAt     time.Time
+}
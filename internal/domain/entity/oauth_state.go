@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// OAuthState sosyal girişte sağlayıcıya yönlendirmeden önce üretilen ve
+// callback'te doğrulanan tek kullanımlık kayıttır
+type OAuthState struct {
+	Provider     string
+	CodeVerifier string // PKCE verifier; sağlayıcıya yalnızca S256 challenge gider
+	Nonce        string // OIDC sağlayıcılarında id_token'a bağlanan değer
+	RedirectURI  string // Girişten sonra token'ların iletileceği istemci adresi
+	CreatedAt    time.Time
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// PasswordHistory kullanıcının önceki şifrelerinin hash'leridir; yeni şifrenin
+// yakın geçmişte kullanılmadığını doğrulamak için tutulur
+type PasswordHistory struct {
+	ID           string `gorm:"primarykey"`
+	UserID       string `gorm:"index;not null"`
+	PasswordHash string `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time
+}
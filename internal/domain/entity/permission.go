@@ -16,4 +16,5 @@ const (
 	PermissionViewMetrics       Permission = "metrics:view"
 	PermissionViewLogs          Permission = "logs:view"
 	PermissionViewAlerts        Permission = "alerts:view"
+	PermissionReset2FA          Permission = "user:reset_2fa"
 )
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// RecoveryCode kimlik doğrulayıcısını kaybeden kullanıcılar için tek
+// kullanımlık 2FA kurtarma kodudur. Kodun kendisi değil SHA-256 özeti saklanır.
+type RecoveryCode struct {
+	ID        string `gorm:"primarykey"`
+	UserID    string `gorm:"index;not null"`
+	CodeHash  string `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -24,6 +24,7 @@ var RolePermissions = map[Role][]Permission{
 		PermissionViewAuditLogs,
 		PermissionViewSecurityLogs,
 		PermissionManageRoles,
+		PermissionReset2FA,
 	},
 	RoleModerator: {
 		PermissionUserBlock,
@@ -33,6 +34,7 @@ var RolePermissions = map[Role][]Permission{
 	RoleSupport: {
 		PermissionViewUserDetails,
 		PermissionResetUserPassword,
+		PermissionReset2FA,
 		PermissionViewAuditLogs,
 	},
 	RoleAnalyst: {
@@ -46,3 +48,23 @@ var RolePermissions = map[Role][]Permission{
 		PermissionViewAlerts,
 	},
 }
+
+// IsValid rolün tanımlı rollerden biri olup olmadığını kontrol eder
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleUser, RoleAdmin, RolePremium, RoleSupport, RoleModerator,
+		RoleAnalyst, RoleSecurityAdmin, RoleSystemMonitor:
+		return true
+	}
+	return false
+}
+
+// HasPermission rolün verilen izne sahip olup olmadığını kontrol eder
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range RolePermissions[r] {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -9,12 +9,19 @@ import (
 type SecurityAction string
 
 const (
-	ActionBlockUser   SecurityAction = "block_user"
-	ActionUnblockUser SecurityAction = "unblock_user"
-	ActionFailedLogin SecurityAction = "failed_login"
-	ActionSuspicious  SecurityAction = "suspicious_activity"
-	ActionRoleChange  SecurityAction = "role_change"
-	ActionForceLogout SecurityAction = "force_logout"
+	ActionBlockUser        SecurityAction = "block_user"
+	ActionUnblockUser      SecurityAction = "unblock_user"
+	ActionFailedLogin      SecurityAction = "failed_login"
+	ActionSuspicious       SecurityAction = "suspicious_activity"
+	ActionRoleChange       SecurityAction = "role_change"
+	ActionForceLogout      SecurityAction = "force_logout"
+	Action2FAChange        SecurityAction = "2fa_change"
+	Action2FAReset         SecurityAction = "2fa_reset"
+	ActionAccountLocked    SecurityAction = "account_locked"
+	ActionKeyRotation      SecurityAction = "key_rotation"
+	ActionPasswordBreached SecurityAction = "password_breached"
+	ActionPasswordReuse    SecurityAction = "password_reuse"
+	ActionForcePassword    SecurityAction = "force_password_change"
 )
 
 type SecurityLog struct {
@@ -2,7 +2,19 @@ package entity
 
 import "time"
 
+// Session oturum verisinde tutulan cihaz/istek bilgisi anahtarları
+const (
+	SessionDevice     = "device"
+	SessionUserAgent  = "user_agent"
+	SessionIP         = "ip"
+	SessionCreatedAt  = "created_at"
+	SessionLastUsedAt = "last_used_at"
+	SessionClientID   = "client_id" // OAuth istemcisine verilmiş oturumlar
+	SessionScope      = "scope"     // OAuth istemcisine verilen kapsamların tamamı
+)
+
 type Session struct {
+	ID        string `json:"-"`
 	UserID    string
 	ExpiresAt time.Time
 	Data      map[string]interface{}
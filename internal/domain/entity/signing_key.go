@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// SigningKey token imzalama anahtarlığının kalıcı kaydıdır. Anahtar
+// ActivatesAt anından itibaren yeni token imzalar, RetiresAt anına kadar
+// eski token'ları doğrulamaya devam eder.
+type SigningKey struct {
+	ID          string `gorm:"primarykey;type:varchar(64)"` // JWT "kid" başlığı
+	Purpose     string `gorm:"type:varchar(20);index;not null"`
+	Algorithm   string `gorm:"type:varchar(10);not null"`
+	KeyMaterial []byte `gorm:"type:text;not null;serializer:encrypted" json:"-"` // Özel anahtar veya HMAC secret'ı; şifreli saklanır
+	ActivatesAt time.Time
+	RetiresAt   *time.Time
+	CreatedBy   string `gorm:"type:varchar(36)"`
+	CreatedAt   time.Time
+}
@@ -9,16 +9,47 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	MFAToken     TokenType = "mfa" // Şifre doğrulandı, ikinci faktör bekleniyor
+)
+
+// Kısıtlı token kapsamları
+const (
+	// ScopeUnverified email adresi doğrulanmamış kullanıcıların kısıtlı token kapsamı
+	ScopeUnverified = "unverified"
+	// ScopePasswordChange yeni şifre belirlemesi gereken kullanıcıların kapsamı;
+	// yalnızca şifre değiştirme uç noktası kullanılabilir
+	ScopePasswordChange = "password_change"
+	// ScopeOfflineAccess OAuth istemcisine refresh token verilmesini sağlar
+	ScopeOfflineAccess = "offline_access"
 )
 
 type TokenClaims struct {
 	jwt.RegisteredClaims
-	UserID string    `json:"user_id"`
-	Role   Role      `json:"role"`
-	Type   TokenType `json:"token_type"`
+	UserID    string    `json:"user_id"`
+	Role      Role      `json:"role"`
+	Type      TokenType `json:"token_type"`
+	SessionID string    `json:"sid,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	// ClientID token'ın kullanıcı adına verildiği OAuth istemcisi; boşsa
+	// token servisin kendi girişinden gelir
+	ClientID string `json:"client_id,omitempty"`
+	// IssuedAtMs iat'in milisaniye hassasiyetli hâli; kullanıcı bazlı iptalle
+	// aynı saniye içinde verilen token'ları ayırt etmek için kullanılır
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 }
 
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	// PasswordChangeRequired kullanıcının şifresini değiştirmesi gerektiğini bildirir
+	PasswordChangeRequired bool `json:"password_change_required,omitempty"`
+}
+
+// OAuthTokenResponse RFC 6749 5.1 token yanıtıdır
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
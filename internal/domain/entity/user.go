@@ -15,31 +15,40 @@ const (
 )
 
 type User struct {
-	ID                     string `gorm:"primarykey"`
-	Email                  string `gorm:"uniqueIndex;not null"`
-	Password               string `gorm:"not null"`
-	Role                   Role   `gorm:"type:varchar(20);default:'user'"`
-	IsVerified             bool   `gorm:"default:false"`
-	HasBlueTick            bool   `gorm:"default:false"`
-	Is2FAEnabled           bool   `gorm:"default:false"`
-	TOTPSecret             string `gorm:"type:varchar(32)"`
-	EmailVerificationToken string `gorm:"type:varchar(100)"`
-	PasswordResetToken     string `gorm:"type:varchar(100)"`
-	TokenExpiresAt         *time.Time
-	IsActive               bool `gorm:"default:true"`
-	BlockedAt              *time.Time
-	BlockedBy              string `gorm:"type:varchar(36)"`
-	BlockReason            string `gorm:"type:text"`
-	LastLoginAt            *time.Time
-	LastLoginIP            string `gorm:"type:varchar(45)"`
-	FailedLoginAttempts    int    `gorm:"default:0"`
-	LastFailedLoginAt      *time.Time
-	CreatedAt              time.Time
-	UpdatedAt              time.Time
-	DeletedAt              gorm.DeletedAt `gorm:"index"`
+	ID                  string `gorm:"primarykey"`
+	Email               string `gorm:"uniqueIndex;not null"`
+	Password            string `gorm:"not null" json:"-"`
+	Role                Role   `gorm:"type:varchar(20);default:'user'"`
+	IsVerified          bool   `gorm:"default:false"`
+	HasBlueTick         bool   `gorm:"default:false"`
+	Is2FAEnabled        bool   `gorm:"default:false"`
+	TOTPSecret          string `gorm:"type:text;serializer:encrypted" json:"-"`
+	PendingTOTPSecret   string `gorm:"type:text;serializer:encrypted" json:"-"` // Doğrulanmayı bekleyen yeni secret
+	IsActive            bool   `gorm:"default:true"`
+	BlockedAt           *time.Time
+	BlockedBy           string `gorm:"type:varchar(36)"`
+	BlockReason         string `gorm:"type:text"`
+	LastLoginAt         *time.Time
+	LastLoginIP         string `gorm:"type:varchar(45)"`
+	FailedLoginAttempts int    `gorm:"default:0"`
+	LastFailedLoginAt   *time.Time
+	LockedUntil         *time.Time
+	MustChangePassword  bool `gorm:"default:false"` // Şifre sızıntı listesinde bulundu, yönetici işaretledi vb.
+	PasswordChangedAt   *time.Time
+	PasswordUnusable    bool `gorm:"default:false"` // Sosyal girişle oluşturuldu, şifre henüz belirlenmedi
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 
 	// İlişkiler
 	Subscriptions []Subscription `gorm:"foreignKey:UserID"`
 	AuditLogs     []AuditLog     `gorm:"foreignKey:UserID"`
 	SecurityLogs  []SecurityLog  `gorm:"foreignKey:UserID"`
 }
+
+// LoginFailureState başarısız giriş sayacının veritabanındaki güncel hâlidir
+type LoginFailureState struct {
+	FailedLoginAttempts int
+	LastFailedLoginAt   *time.Time
+	LockedUntil         *time.Time
+}
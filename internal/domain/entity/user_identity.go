@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// UserIdentity kullanıcının bir sosyal giriş sağlayıcısındaki hesabını temsil
+// eder; (Provider, Subject) çifti sağlayıcı genelinde benzersizdir
+type UserIdentity struct {
+	ID        string    `gorm:"primarykey" json:"id"`
+	UserID    string    `gorm:"index;not null" json:"-"`
+	Provider  string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email     string    `gorm:"type:varchar(255)" json:"email"` // Bağlanma anındaki sağlayıcı emaili
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PendingIdentityLink emaili mevcut bir hesapla eşleşen sosyal girişin, hesap
+// sahibi şifresini doğrulayana kadar bekleyen bağlama isteğidir
+type PendingIdentityLink struct {
+	UserID    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
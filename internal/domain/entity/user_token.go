@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// TokenPurpose email ile gönderilen token'ın hangi işlem için geçerli olduğunu belirler
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	TokenPurposeEmailVerify   TokenPurpose = "email_verify"
+)
+
+// UserToken email ile gönderilen tek kullanımlık, amaca bağlı token'dır.
+// Token'ın kendisi değil SHA-256 özeti saklanır; kullanıcı başına her amaç
+// için yalnızca en son üretilen token geçerlidir.
+type UserToken struct {
+	ID        string       `gorm:"primarykey"`
+	UserID    string       `gorm:"index;not null"`
+	Purpose   TokenPurpose `gorm:"type:varchar(30);not null"`
+	TokenHash string       `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,91 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+// GitHubProvider OIDC desteklemeyen GitHub için REST API'den kullanıcıyı ve
+// doğrulanmış birincil email adresini okur
+type GitHubProvider struct {
+	config     *oauth2.Config
+	httpClient *http.Client
+}
+
+func NewGitHubProvider(clientID, clientSecret, redirectURL string, httpClient *http.Client) *GitHubProvider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &GitHubProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint:     github.Endpoint,
+		},
+		httpClient: httpClient,
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) GetAuthURL(req AuthRequest) string {
+	return p.config.AuthCodeURL(req.State, oauth2.S256ChallengeOption(req.CodeVerifier))
+}
+
+func (p *GitHubProvider) GetUserInfo(ctx context.Context, code string, req AuthRequest) (*UserInfo, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %v", err)
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := fetchJSON(ctx, p.httpClient, githubAPIURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed getting user info: %v", err)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("failed getting user info: kullanıcı kimliği yok")
+	}
+
+	// /user yanıtındaki email profilde herkese açık olandır ve doğrulanmış
+	// olması gerekmez; birincil adres doğrulama bilgisiyle ayrıca alınır
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := fetchJSON(ctx, p.httpClient, githubAPIURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed getting user emails: %v", err)
+	}
+
+	info := &UserInfo{
+		ID:       strconv.FormatInt(user.ID, 10), // login değiştirilebilir, id değişmez
+		Name:     user.Name,
+		Provider: p.Name(),
+	}
+	if info.Name == "" {
+		info.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			info.Email = email.Email
+			info.EmailVerified = email.Verified
+		}
+	}
+	return info, nil
+}
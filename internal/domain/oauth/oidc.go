@@ -0,0 +1,393 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"auth-service/pkg/security"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oauthMaxResponseSize sağlayıcı API yanıtları için üst sınır
+	oauthMaxResponseSize = 1 << 20
+	// oidcClockSkew sağlayıcı ile aramızdaki saat farkı toleransı
+	oidcClockSkew = time.Minute
+	// jwksRefreshInterval bilinmeyen kid için JWKS en fazla bu sıklıkla yeniden çekilir
+	jwksRefreshInterval = time.Minute
+)
+
+var ErrInvalidIDToken = errors.New("id_token doğrulanamadı")
+
+// oidcSupportedAlgorithms açık anahtarla doğrulanabilen algoritmalar; HS* ve
+// "none" bilerek yoktur
+var oidcSupportedAlgorithms = map[string]bool{
+	"RS256": true, "RS384": true, "RS512": true,
+	"PS256": true, "PS384": true, "PS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+	"EdDSA": true,
+}
+
+// OIDCConfig discovery ile yapılandırılan bir OpenID Connect sağlayıcısı
+type OIDCConfig struct {
+	Name         string // Route ve UserInfo.Provider için kısa ad, ör. "okta"
+	IssuerURL    string // Discovery belgesindeki issuer ile birebir aynı olmalıdır
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string     // Boşsa openid, email, profile
+	HTTPClient   *http.Client // Boşsa http.DefaultClient
+	// ClientSecretFunc tanımlıysa her code exchange'de ClientSecret yerine
+	// kullanılır (ör. Apple'ın imzalı JWT client secret'ı)
+	ClientSecretFunc func() (string, error)
+	// ResponseMode "form_post" ise sağlayıcı callback'i POST gövdesiyle yapar
+	ResponseMode string
+	// MultiTenant issuer'ında {tenantid} yer tutucusu bulunan çok kiracılı
+	// sağlayıcılar içindir; beklenen issuer id_token'ın tid claim'iyle tamamlanır
+	MultiTenant bool
+}
+
+// OIDCProvider discovery belgesindeki uç noktaları kullanır ve kimliği
+// imzası issuer'ın JWKS'iyle doğrulanan id_token'dan alır
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	multiTenant  bool
+	config       *oauth2.Config
+	secretFunc   func() (string, error)
+	responseMode string
+	userInfoURL  string
+	jwksURL      string
+	algorithms   []string
+	httpClient   *http.Client
+
+	mu            sync.Mutex
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserInfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgorithms     []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string   `json:"nonce"`
+	AuthorizedParty string   `json:"azp"`
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	Name            string   `json:"name"`
+	TenantID        string   `json:"tid"`
+}
+
+// flexBool bazı sağlayıcıların email_verified'ı "true" string'i olarak
+// göndermesine karşı her iki biçimi de kabul eder
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*b = flexBool(v)
+	case string:
+		*b = flexBool(strings.EqualFold(v, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// NewOIDCProvider issuer'ın .well-known/openid-configuration belgesini okur
+// ve sağlayıcıyı buna göre yapılandırır
+func NewOIDCProvider(ctx context.Context, cfg OIDCConfig) (*OIDCProvider, error) {
+	if cfg.Name == "" || cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("OIDC sağlayıcısı için ad, issuer ve client id gereklidir")
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	p := &OIDCProvider{
+		name:         cfg.Name,
+		issuer:       cfg.IssuerURL,
+		secretFunc:   cfg.ClientSecretFunc,
+		responseMode: cfg.ResponseMode,
+		httpClient:   httpClient,
+		keys:         map[string]crypto.PublicKey{},
+	}
+
+	var discovery oidcDiscovery
+	discoveryURL := strings.TrimSuffix(cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := fetchJSON(ctx, httpClient, discoveryURL, "", &discovery); err != nil {
+		return nil, fmt.Errorf("%s discovery belgesi okunamadı: %w", cfg.Name, err)
+	}
+	if cfg.MultiTenant && strings.Contains(discovery.Issuer, "{tenantid}") {
+		p.issuer = discovery.Issuer
+		p.multiTenant = true
+	} else if discovery.Issuer != cfg.IssuerURL {
+		// Belgeyi başka bir issuer'ın sunması kabul edilmez (OIDC Discovery §4.3)
+		return nil, fmt.Errorf("%s discovery issuer uyuşmuyor: %q beklenirken %q", cfg.Name, cfg.IssuerURL, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%s discovery belgesinde zorunlu uç noktalar eksik", cfg.Name)
+	}
+
+	for _, alg := range discovery.SigningAlgorithms {
+		if oidcSupportedAlgorithms[alg] {
+			p.algorithms = append(p.algorithms, alg)
+		}
+	}
+	if len(discovery.SigningAlgorithms) == 0 {
+		// RS256 her OIDC sağlayıcısı için zorunludur
+		p.algorithms = []string{"RS256"}
+	}
+	if len(p.algorithms) == 0 {
+		return nil, fmt.Errorf("%s desteklenen bir id_token imza algoritması sunmuyor", cfg.Name)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	hasOpenID := false
+	for _, scope := range scopes {
+		hasOpenID = hasOpenID || scope == "openid"
+	}
+	if !hasOpenID {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	p.userInfoURL = discovery.UserInfoEndpoint
+	p.jwksURL = discovery.JWKSURI
+	p.config = &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+	return p, nil
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) GetAuthURL(req AuthRequest) string {
+	opts := []oauth2.AuthCodeOption{
+		oauth2.S256ChallengeOption(req.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", req.Nonce),
+	}
+	if p.responseMode != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("response_mode", p.responseMode))
+	}
+	return p.config.AuthCodeURL(req.State, opts...)
+}
+
+// UsesFormPost callback'in POST gövdesiyle gelip gelmediğini bildirir
+func (p *OIDCProvider) UsesFormPost() bool {
+	return p.responseMode == "form_post"
+}
+
+func (p *OIDCProvider) GetUserInfo(ctx context.Context, code string, req AuthRequest) (*UserInfo, error) {
+	config := p.config
+	if p.secretFunc != nil {
+		secret, err := p.secretFunc()
+		if err != nil {
+			return nil, fmt.Errorf("client secret üretilemedi: %w", err)
+		}
+		config = &oauth2.Config{
+			ClientID:     p.config.ClientID,
+			ClientSecret: secret,
+			RedirectURL:  p.config.RedirectURL,
+			Scopes:       p.config.Scopes,
+			Endpoint:     p.config.Endpoint,
+		}
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %v", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token yanıtında id_token yok", ErrInvalidIDToken)
+	}
+	claims, err := p.verifyIDToken(ctx, rawIDToken, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Provider:      p.name,
+	}
+
+	// Bazı sağlayıcılar email'i id_token'a koymaz; userinfo'dan tamamlanır
+	if info.Email == "" && p.userInfoURL != "" {
+		var extra struct {
+			Subject       string   `json:"sub"`
+			Email         string   `json:"email"`
+			EmailVerified flexBool `json:"email_verified"`
+			Name          string   `json:"name"`
+		}
+		if err := fetchJSON(ctx, p.httpClient, p.userInfoURL, token.AccessToken, &extra); err != nil {
+			return nil, fmt.Errorf("failed getting user info: %v", err)
+		}
+		// Yanıt başka bir kullanıcıya aitse kullanılmaz (OIDC Core §5.3.2)
+		if extra.Subject != claims.Subject {
+			return nil, fmt.Errorf("%w: userinfo subject uyuşmuyor", ErrInvalidIDToken)
+		}
+		info.Email = extra.Email
+		info.EmailVerified = bool(extra.EmailVerified)
+		if info.Name == "" {
+			info.Name = extra.Name
+		}
+	}
+
+	return info, nil
+}
+
+// verifyIDToken imzayı JWKS ile, ardından issuer, audience, süre ve nonce'u doğrular
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, raw, nonce string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods(p.algorithms),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	expectedIssuer := p.issuer
+	if p.multiTenant {
+		if claims.TenantID == "" {
+			return nil, fmt.Errorf("%w: tid yok", ErrInvalidIDToken)
+		}
+		expectedIssuer = strings.ReplaceAll(p.issuer, "{tenantid}", claims.TenantID)
+	}
+	if claims.Issuer != expectedIssuer {
+		return nil, fmt.Errorf("%w: issuer uyuşmuyor", ErrInvalidIDToken)
+	}
+	// exp zorunludur; kütüphane yalnızca varsa kontrol eder
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: exp yok", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sub yok", ErrInvalidIDToken)
+	}
+	// Birden fazla audience varsa token bize verilmiş olmalıdır (OIDC Core §3.1.3.7)
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp uyuşmuyor", ErrInvalidIDToken)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce uyuşmuyor", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// publicKey kid'e ait anahtarı döner; bilinmeyen kid sağlayıcının anahtar
+// rotasyonu olabileceğinden JWKS'i (sınırlı sıklıkla) yeniden çeker
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("imza anahtarı bulunamadı: %q", kid)
+	}
+
+	var jwks security.JWKS
+	if err := fetchJSON(ctx, p.httpClient, p.jwksURL, "", &jwks); err != nil {
+		return nil, fmt.Errorf("JWKS okunamadı: %w", err)
+	}
+	p.keysFetchedAt = time.Now()
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// Desteklenmeyen tek bir anahtar setin geri kalanını geçersiz kılmaz
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	p.keys = keys
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("imza anahtarı bulunamadı: %q", kid)
+}
+
+// lookupKey kid'siz token'lar için setteki tek anahtarı kabul eder
+func (p *OIDCProvider) lookupKey(kid string) crypto.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+// fetchJSON sağlayıcı API'lerinden boyutu sınırlı JSON yanıtı okur
+func fetchJSON(ctx context.Context, client *http.Client, url, bearer string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s beklenmeyen durum kodu: %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, oauthMaxResponseSize)).Decode(dst)
+}
@@ -0,0 +1,114 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googleIssuer = "https://accounts.google.com"
+	appleIssuer  = "https://appleid.apple.com"
+	// appleClientSecretTTL Apple'ın kabul ettiği en uzun süre 6 aydır; her
+	// exchange'de yeniden üretildiği için kısa tutulur
+	appleClientSecretTTL = 5 * time.Minute
+	// DefaultGitLabURL kendi sunucusunu kullanmayanlar için gitlab.com
+	DefaultGitLabURL = "https://gitlab.com"
+)
+
+// NewGoogleProvider Google hesaplarını OIDC ile kullanır; kimlik userinfo
+// yerine imzası, audience'ı ve nonce'u doğrulanan id_token'dan alınır
+func NewGoogleProvider(ctx context.Context, clientID, clientSecret, redirectURL string, httpClient *http.Client) (*OIDCProvider, error) {
+	return NewOIDCProvider(ctx, OIDCConfig{
+		Name:         "google",
+		IssuerURL:    googleIssuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		HTTPClient:   httpClient,
+	})
+}
+
+// NewMicrosoftProvider Microsoft Entra ID (Azure AD) v2.0 uç noktasını kullanır.
+// tenant boşsa "common" (kişisel ve kurumsal hesaplar) kullanılır; çok kiracılı
+// modda issuer token'daki tid ile doğrulanır. Entra email_verified claim'i
+// göndermediğinden bu sağlayıcıdan gelen email doğrulanmamış sayılır.
+func NewMicrosoftProvider(ctx context.Context, tenant, clientID, clientSecret, redirectURL string, httpClient *http.Client) (*OIDCProvider, error) {
+	if tenant == "" {
+		tenant = "common"
+	}
+	return NewOIDCProvider(ctx, OIDCConfig{
+		Name:         "microsoft",
+		IssuerURL:    "https://login.microsoftonline.com/" + tenant + "/v2.0",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		HTTPClient:   httpClient,
+		MultiTenant:  true,
+	})
+}
+
+// NewGitLabProvider gitlab.com veya kendi sunucusundaki GitLab'ı OIDC ile kullanır
+func NewGitLabProvider(ctx context.Context, baseURL, clientID, clientSecret, redirectURL string, httpClient *http.Client) (*OIDCProvider, error) {
+	if baseURL == "" {
+		baseURL = DefaultGitLabURL
+	}
+	return NewOIDCProvider(ctx, OIDCConfig{
+		Name:         "gitlab",
+		IssuerURL:    strings.TrimSuffix(baseURL, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		HTTPClient:   httpClient,
+	})
+}
+
+// NewAppleProvider Sign in with Apple'ı kullanır. clientID Services ID'dir;
+// client secret, Apple geliştirici hesabındaki .p8 anahtarıyla imzalanan
+// ES256 JWT'dir. email ve name kapsamları istendiğinde Apple callback'i
+// yalnızca form_post ile yapar.
+func NewAppleProvider(ctx context.Context, clientID, teamID, keyID, privateKeyPath, redirectURL string, httpClient *http.Client) (*OIDCProvider, error) {
+	if teamID == "" || keyID == "" || privateKeyPath == "" {
+		return nil, fmt.Errorf("apple için team id, key id ve özel anahtar gereklidir")
+	}
+	pemBytes, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("apple özel anahtarı okunamadı: %w", err)
+	}
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("apple özel anahtarı geçersiz: %w", err)
+	}
+
+	clientSecret := func() (string, error) {
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			Issuer:    teamID,
+			Subject:   clientID,
+			Audience:  jwt.ClaimStrings{appleIssuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(appleClientSecretTTL)),
+		})
+		token.Header["kid"] = keyID
+		return token.SignedString(privateKey)
+	}
+
+	return NewOIDCProvider(ctx, OIDCConfig{
+		Name:             "apple",
+		IssuerURL:        appleIssuer,
+		ClientID:         clientID,
+		RedirectURL:      redirectURL,
+		Scopes:           []string{"openid", "email", "name"},
+		HTTPClient:       httpClient,
+		ClientSecretFunc: clientSecret,
+		ResponseMode:     "form_post",
+	})
+}
@@ -0,0 +1,253 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"auth-service/pkg/security"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "test-client"
+	testNonce    = "test-nonce"
+	testKeyID    = "k1"
+)
+
+// newTestIssuer discovery ve JWKS sunan sahte bir OIDC sağlayıcısı kurar
+func newTestIssuer(t *testing.T, algorithms []string) (*httptest.Server, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                server.URL,
+			AuthorizationEndpoint: server.URL + "/authorize",
+			TokenEndpoint:         server.URL + "/token",
+			JWKSURI:               server.URL + "/jwks",
+			SigningAlgorithms:     algorithms,
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(security.JWKS{Keys: []security.JWK{{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: "RS256",
+			KeyID:     testKeyID,
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	return server, key
+}
+
+func newTestProvider(t *testing.T, server *httptest.Server) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		Name:       "test",
+		IssuerURL:  server.URL,
+		ClientID:   testClientID,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	server, key := newTestIssuer(t, []string{"RS256", "HS256"})
+	p := newTestProvider(t, server)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validClaims := func() jwt.MapClaims {
+		now := time.Now()
+		return jwt.MapClaims{
+			"iss":   server.URL,
+			"sub":   "user-1",
+			"aud":   testClientID,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+			"nonce": testNonce,
+		}
+	}
+	signRS256 := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = testKeyID
+		raw, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	with := func(changes jwt.MapClaims) string {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return signRS256(claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		valid bool
+	}{
+		{name: "geçerli", token: with(nil), nonce: testNonce, valid: true},
+		{name: "azp istemciyle aynı", token: with(jwt.MapClaims{"aud": []string{testClientID, "other"}, "azp": testClientID}), nonce: testNonce, valid: true},
+		{name: "yanlış aud", token: with(jwt.MapClaims{"aud": "other-client"}), nonce: testNonce},
+		{name: "aud yok", token: with(jwt.MapClaims{"aud": nil}), nonce: testNonce},
+		{name: "yanlış iss", token: with(jwt.MapClaims{"iss": "https://evil.example.com"}), nonce: testNonce},
+		{name: "exp yok", token: with(jwt.MapClaims{"exp": nil}), nonce: testNonce},
+		{name: "süresi dolmuş", token: with(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), nonce: testNonce},
+		{name: "sub yok", token: with(jwt.MapClaims{"sub": nil}), nonce: testNonce},
+		{name: "birden fazla aud, azp yok", token: with(jwt.MapClaims{"aud": []string{testClientID, "other"}}), nonce: testNonce},
+		{name: "azp başka istemci", token: with(jwt.MapClaims{"azp": "other"}), nonce: testNonce},
+		{name: "nonce yok", token: with(jwt.MapClaims{"nonce": nil}), nonce: testNonce},
+		{name: "nonce uyuşmuyor", token: with(jwt.MapClaims{"nonce": "other-nonce"}), nonce: testNonce},
+		{name: "beklenen nonce boş", token: with(jwt.MapClaims{"nonce": ""}), nonce: ""},
+		{
+			name: "alg none",
+			token: func() string {
+				raw, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			}(),
+			nonce: testNonce,
+		},
+		{
+			// Açık anahtarı HMAC secret'ı olarak kullanan algoritma karıştırma saldırısı
+			name: "HS256 açık anahtarla",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+				token.Header["kid"] = testKeyID
+				raw, err := token.SignedString(publicDER)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			}(),
+			nonce: testNonce,
+		},
+		{
+			name: "başka anahtarla imzalı",
+			token: func() string {
+				other, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					t.Fatal(err)
+				}
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+				token.Header["kid"] = testKeyID
+				raw, err := token.SignedString(other)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			}(),
+			nonce: testNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := p.verifyIDToken(context.Background(), tt.token, tt.nonce)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("beklenmeyen hata: %v", err)
+				}
+				if claims.Subject != "user-1" {
+					t.Fatalf("sub = %q", claims.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("ErrInvalidIDToken bekleniyordu, alınan: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewOIDCProviderAlgorithms(t *testing.T) {
+	tests := []struct {
+		name       string
+		advertised []string
+		want       []string
+		wantErr    bool
+	}{
+		{name: "belirtilmemiş", advertised: nil, want: []string{"RS256"}},
+		{name: "simetrik ve none elenir", advertised: []string{"none", "HS256", "ES256", "RS256"}, want: []string{"ES256", "RS256"}},
+		{name: "yalnızca simetrik", advertised: []string{"HS256", "none"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestIssuer(t, tt.advertised)
+			p, err := NewOIDCProvider(context.Background(), OIDCConfig{
+				Name:       "test",
+				IssuerURL:  server.URL,
+				ClientID:   testClientID,
+				HTTPClient: server.Client(),
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("hata bekleniyordu, algoritmalar: %v", p.algorithms)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.algorithms) != len(tt.want) {
+				t.Fatalf("algoritmalar = %v, beklenen %v", p.algorithms, tt.want)
+			}
+			for i := range tt.want {
+				if p.algorithms[i] != tt.want[i] {
+					t.Fatalf("algoritmalar = %v, beklenen %v", p.algorithms, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOIDCProviderIssuerMismatch(t *testing.T) {
+	server, _ := newTestIssuer(t, nil)
+	// Discovery aynı adresten okunur ama issuer birebir aynı değildir
+	_, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		Name:       "test",
+		IssuerURL:  server.URL + "/",
+		ClientID:   testClientID,
+		HTTPClient: server.Client(),
+	})
+	if err == nil {
+		t.Fatal("discovery issuer uyuşmazlığı kabul edildi")
+	}
+}
@@ -2,76 +2,71 @@ package oauth
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
+	"sort"
 )
 
 type Provider interface {
-	GetAuthURL() string
-	GetUserInfo(code string) (*UserInfo, error)
+	// Name sağlayıcının kısa adıdır (ör. "google"); route'larda, state
+	// kayıtlarında ve UserInfo.Provider alanında kullanılır
+	Name() string
+	// GetAuthURL state, nonce ve PKCE verifier'ın S256 challenge'ı ile yetkilendirme adresini üretir
+	GetAuthURL(req AuthRequest) string
+	// GetUserInfo code'u aynı verifier ile token'a çevirip kullanıcı bilgisini alır
+	GetUserInfo(ctx context.Context, code string, req AuthRequest) (*UserInfo, error)
 }
 
-type UserInfo struct {
-	ID       string
-	Email    string
-	Name     string
-	Provider string
+// FormPoster callback'i sorgu yerine POST gövdesiyle (response_mode=form_post)
+// yapan sağlayıcılarca uygulanır
+type FormPoster interface {
+	UsesFormPost() bool
 }
 
-type GoogleProvider struct {
-	config *oauth2.Config
+// AuthRequest yönlendirmede üretilip callback'te tekrar kullanılan tek
+// kullanımlık değerlerdir
+type AuthRequest struct {
+	State        string
+	CodeVerifier string // PKCE verifier; sağlayıcıya yalnızca S256 challenge gider
+	Nonce        string // OIDC nonce; id_token içinde aynen geri dönmelidir
 }
 
-func NewGoogleProvider(clientID, clientSecret, redirectURL string) *GoogleProvider {
-	return &GoogleProvider{
-		config: &oauth2.Config{
-			ClientID:     clientID,
-			ClientSecret: clientSecret,
-			RedirectURL:  redirectURL,
-			Scopes: []string{
-				"https://www.googleapis.com/auth/userinfo.email",
-				"https://www.googleapis.com/auth/userinfo.profile",
-			},
-			Endpoint: google.Endpoint,
-		},
-	}
+type UserInfo struct {
+	ID            string // Sağlayıcıdaki değişmez kullanıcı kimliği (subject)
+	Email         string
+	EmailVerified bool // Sağlayıcı email adresinin sahibini doğruladı mı
+	Name          string
+	Provider      string
 }
 
-func (p *GoogleProvider) GetAuthURL() string {
-	return p.config.AuthCodeURL("state")
+// Registry etkin sosyal giriş sağlayıcılarını adlarıyla tutar
+type Registry struct {
+	providers map[string]Provider
 }
 
-func (p *GoogleProvider) GetUserInfo(code string) (*UserInfo, error) {
-	token, err := p.config.Exchange(context.Background(), code)
-	if err != nil {
-		return nil, fmt.Errorf("code exchange failed: %v", err)
-	}
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]Provider{}}
+}
 
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + token.AccessToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed getting user info: %v", err)
+// Register sağlayıcıyı ekler; aynı ad iki kez kullanılamaz
+func (r *Registry) Register(provider Provider) error {
+	if _, exists := r.providers[provider.Name()]; exists {
+		return fmt.Errorf("sağlayıcı adı tekrar kullanılmış: %s", provider.Name())
 	}
-	defer resp.Body.Close()
+	r.providers[provider.Name()] = provider
+	return nil
+}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed reading response body: %v", err)
-	}
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("failed parsing response: %v", err)
+// Names etkin sağlayıcı adlarını alfabetik sırayla döner
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
 	}
-
-	return &UserInfo{
-		ID:       result["id"].(string),
-		Email:    result["email"].(string),
-		Name:     result["name"].(string),
-		Provider: "google",
-	}, nil
+	sort.Strings(names)
+	return names
 }
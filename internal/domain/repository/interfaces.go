@@ -10,9 +10,17 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	Update(ctx context.Context, user *entity.User) error
+	// UpdateFields yalnızca verilen alanları kullanıcının güncel değerleriyle
+	// yazar; önceden okunmuş kaydın diğer kolonları (kilit sayacı, engel
+	// durumu) eşzamanlı değişikliklerin üzerine yazılmaz
+	UpdateFields(ctx context.Context, user *entity.User, fields ...string) error
+	// IncrementFailedLogins sayacı satırın güncel değeri üzerinden artırır;
+	// threshold > 0 ve sayaç eşiğe ulaştıysa hesabı lockedUntil'a kadar kilitler
+	IncrementFailedLogins(ctx context.Context, id string, at time.Time, threshold int, lockedUntil time.Time) (*entity.LoginFailureState, error)
+	// RecordSuccessfulLogin yalnızca sayaç, kilit ve son giriş kolonlarını günceller
+	RecordSuccessfulLogin(ctx context.Context, id string, at time.Time, ip string) error
 	GetByID(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
-	GetByResetToken(ctx context.Context, token string) (*entity.User, error)
 	List(ctx context.Context, offset, limit int) ([]entity.User, error)
 	GetActiveCount(ctx context.Context) (int, error)
 	GetBlockedCount(ctx context.Context) (int, error)
@@ -28,8 +36,53 @@ type SecurityRepository interface {
 type SessionRepository interface {
 	Create(ctx context.Context, sessionID string, session *entity.Session, ttl time.Duration) error
 	Get(ctx context.Context, sessionID string) (*entity.Session, error)
+	// CompareAndSwap saklanan oturumun Data[field] değeri expected ise oturumu
+	// atomik olarak session ile değiştirir; oturum yoksa veya değer farklıysa false döner
+	CompareAndSwap(ctx context.Context, sessionID, field, expected string, session *entity.Session, ttl time.Duration) (bool, error)
 	Delete(ctx context.Context, sessionID string) error
 	DeleteAllUserSessions(ctx context.Context, userID string) error
+	ListUserSessions(ctx context.Context, userID string) ([]*entity.Session, error)
+	// ListClientSessions OAuth istemcisine verilmiş aktif oturumları döner
+	ListClientSessions(ctx context.Context, clientID string) ([]*entity.Session, error)
+}
+
+// TokenRepository iptal edilmiş token'ları (jti / oturum kimliği) ve kullanıcı
+// bazlı "bu andan önce üretilen token'lar geçersiz" zaman damgalarını tutar
+type TokenRepository interface {
+	Revoke(ctx context.Context, tokenID string) error
+	IsRevoked(ctx context.Context, tokenIDs ...string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID string, at time.Time) error
+	GetUserRevokedAt(ctx context.Context, userID string) (*time.Time, error)
+}
+
+type OAuthStateRepository interface {
+	Save(ctx context.Context, state string, data *entity.OAuthState, ttl time.Duration) error
+	// Consume state kaydını okuyup siler; bulunamazsa nil döner
+	Consume(ctx context.Context, state string) (*entity.OAuthState, error)
+}
+
+// AuthorizationCodeRepository /oauth/authorize ile verilen code'ları saklar
+type AuthorizationCodeRepository interface {
+	Save(ctx context.Context, code string, data *entity.AuthorizationCode, ttl time.Duration) error
+	// Consume code kaydını okuyup siler; bulunamazsa nil döner
+	Consume(ctx context.Context, code string) (*entity.AuthorizationCode, error)
+}
+
+// OAuthLinkRepository şifre doğrulaması bekleyen hesap bağlama isteklerini saklar
+type OAuthLinkRepository interface {
+	Save(ctx context.Context, token string, link *entity.PendingIdentityLink, ttl time.Duration) error
+	Get(ctx context.Context, token string) (*entity.PendingIdentityLink, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	ListByUser(ctx context.Context, userID string) ([]entity.UserIdentity, error)
+	// Delete kimlik kullanıcıya aitse siler; silinmediyse false döner
+	Delete(ctx context.Context, userID, id string) (bool, error)
+	// DeleteAllForUser kullanıcının tüm bağlı hesaplarını siler ve sayısını döner
+	DeleteAllForUser(ctx context.Context, userID string) (int, error)
 }
 
 type AuditRepository interface {
@@ -38,6 +91,24 @@ type AuditRepository interface {
 	GetByDateRange(ctx context.Context, from, to time.Time) ([]entity.AuditLog, error)
 }
 
+type UserTokenRepository interface {
+	// Replace kullanıcının aynı amaçlı eski token'larını silip yenisini kaydeder
+	Replace(ctx context.Context, token *entity.UserToken) error
+	// GetValid özeti ve amacı eşleşen, kullanılmamış ve süresi dolmamış token'ı döner
+	GetValid(ctx context.Context, tokenHash string, purpose entity.TokenPurpose) (*entity.UserToken, error)
+	// Consume token'ı atomik olarak kullanılmış işaretler
+	Consume(ctx context.Context, id string) (bool, error)
+	DeleteForUser(ctx context.Context, userID string, purpose entity.TokenPurpose) error
+}
+
+type RecoveryCodeRepository interface {
+	// ReplaceForUser kullanıcının mevcut tüm kodlarını silip yenilerini kaydeder
+	ReplaceForUser(ctx context.Context, userID string, codes []entity.RecoveryCode) error
+	// Consume kullanılmamış kodu atomik olarak kullanılmış işaretler
+	Consume(ctx context.Context, userID, codeHash string) (bool, error)
+	CountUnused(ctx context.Context, userID string) (int, error)
+}
+
 type SubscriptionRepository interface {
 	Create(ctx context.Context, subscription *entity.Subscription) error
 	GetByUserID(ctx context.Context, userID string) ([]entity.Subscription, error)
@@ -56,3 +127,29 @@ type SecurityAlert struct {
 	CreatedAt   time.Time
 	Metadata    map[string]interface{}
 }
+
+// SigningKeyRepository token imzalama anahtarlarını saklar
+type SigningKeyRepository interface {
+	// Create kaydı ekler; aynı kimlikli kayıt varsa dokunmaz
+	Create(ctx context.Context, key *entity.SigningKey) error
+	// ListUsable henüz emekliye ayrılmamış anahtarları döner
+	ListUsable(ctx context.Context, now time.Time) ([]entity.SigningKey, error)
+	// Rotate tek transaction'da amaca ait, emeklilik zamanı belirlenmemiş
+	// anahtarlara emeklilik zamanı atar ve yeni anahtarı ekler
+	Rotate(ctx context.Context, purpose string, retiresAt time.Time, key *entity.SigningKey) error
+}
+
+// OAuthClientRepository servis istemcilerini saklar
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *entity.OAuthClient) error
+	GetByID(ctx context.Context, id string) (*entity.OAuthClient, error)
+	List(ctx context.Context) ([]entity.OAuthClient, error)
+	Update(ctx context.Context, client *entity.OAuthClient) error
+}
+
+// PasswordHistoryRepository kullanıcıların önceki şifre hash'lerini saklar
+type PasswordHistoryRepository interface {
+	// Add kaydı ekler ve kullanıcının en yeni keep kaydı dışındakileri siler
+	Add(ctx context.Context, entry *entity.PasswordHistory, keep int) error
+	ListRecent(ctx context.Context, userID string, limit int) ([]entity.PasswordHistory, error)
+}
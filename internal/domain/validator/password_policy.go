@@ -0,0 +1,245 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Şifre kuralı ihlal kodları; istemciler mesajları bu kodlara göre yerelleştirir
+const (
+	CodePasswordTooShort      = "password_too_short"
+	CodePasswordTooLong       = "password_too_long"
+	CodePasswordMissingUpper  = "password_missing_upper"
+	CodePasswordMissingLower  = "password_missing_lower"
+	CodePasswordMissingDigit  = "password_missing_digit"
+	CodePasswordMissingSymbol = "password_missing_symbol"
+	CodePasswordHasSpace      = "password_has_space"
+	CodePasswordRepeatedChars = "password_repeated_chars"
+	CodePasswordBannedWord    = "password_contains_banned_word"
+	CodePasswordTooWeak       = "password_too_weak"
+	CodePasswordBreached      = "password_breached" // Sızıntı veri seti kontrolü (servis katmanı)
+	CodePasswordReused        = "password_reused"   // Şifre geçmişi kontrolü (servis katmanı)
+)
+
+// PasswordPolicy yapılandırmadan yüklenen şifre kurallarıdır. Uzunluklar bayt
+// değil karakter (rune) sayısıdır; büyük/küçük harf ayrımı olmayan yazı
+// sistemlerindeki harfler harf şartlarını karşılar.
+type PasswordPolicy struct {
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length"`
+	// PassphraseMinLength bu uzunluktaki şifrelerden karakter sınıfı şartı aranmaz (0: kapalı)
+	PassphraseMinLength int      `json:"passphrase_min_length,omitempty"`
+	RequireUpper        bool     `json:"require_upper"`
+	RequireLower        bool     `json:"require_lower"`
+	RequireDigit        bool     `json:"require_digit"`
+	RequireSymbol       bool     `json:"require_symbol"`
+	AllowSpaces         bool     `json:"allow_spaces"`
+	MaxRepeated         int      `json:"max_repeated,omitempty"`     // Aynı karakterin arka arkaya en fazla tekrarı (0: sınırsız)
+	MinEntropyBits      float64  `json:"min_entropy_bits,omitempty"` // EstimateEntropy ile hesaplanan alt sınır (0: kapalı)
+	BannedWords         []string `json:"banned_words,omitempty"`     // Ürün adı gibi şifrede geçemeyecek kelimeler
+}
+
+// DefaultPasswordPolicy yapılandırma verilmediğinde kullanılan kurallar
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:           8,
+	MaxLength:           128,
+	PassphraseMinLength: 20,
+	RequireUpper:        true,
+	RequireLower:        true,
+	RequireDigit:        true,
+	AllowSpaces:         true,
+	MaxRepeated:         3,
+}
+
+// PolicyViolation tek bir kural ihlalidir; Message varsayılan (Türkçe) metindir
+type PolicyViolation struct {
+	Code    string                 `json:"code"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Message string                 `json:"message"`
+}
+
+// PasswordPolicyError şifrenin ihlal ettiği tüm kuralları taşır
+type PasswordPolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap ihlalleri karşılık gelen hata değerlerine eşler, böylece
+// errors.Is(err, ErrPasswordTooShort) gibi kontroller çalışmaya devam eder
+func (e *PasswordPolicyError) Unwrap() []error {
+	var errs []error
+	for _, violation := range e.Violations {
+		switch violation.Code {
+		case CodePasswordTooShort:
+			errs = append(errs, ErrPasswordTooShort)
+		case CodePasswordHasSpace:
+			errs = append(errs, ErrPasswordHasSpace)
+		case CodePasswordMissingUpper, CodePasswordMissingLower, CodePasswordMissingDigit:
+			errs = append(errs, ErrPasswordTooWeak)
+		}
+	}
+	return errs
+}
+
+// Validate şifreyi kurallara göre denetler. userInputs, email'in yerel kısmı
+// gibi kullanıcıya ait ve şifrede geçmemesi gereken değerlerdir.
+func (p PasswordPolicy) Validate(password string, userInputs ...string) error {
+	var violations []PolicyViolation
+	add := func(code, message string, params map[string]interface{}) {
+		violations = append(violations, PolicyViolation{Code: code, Params: params, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add(CodePasswordTooShort, fmt.Sprintf("şifre en az %d karakter olmalıdır", p.MinLength), map[string]interface{}{"min": p.MinLength})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(CodePasswordTooLong, fmt.Sprintf("şifre en fazla %d karakter olabilir", p.MaxLength), map[string]interface{}{"max": p.MaxLength})
+	}
+
+	classes := classify(password)
+	if classes.space && !p.AllowSpaces {
+		add(CodePasswordHasSpace, "şifre boşluk içeremez", nil)
+	}
+
+	// Uzun parolalarda (passphrase) karakter sınıfı şartı aranmaz
+	if p.PassphraseMinLength == 0 || length < p.PassphraseMinLength {
+		if p.RequireUpper && !classes.upper && !classes.uncased {
+			add(CodePasswordMissingUpper, "şifre en az bir büyük harf içermelidir", nil)
+		}
+		if p.RequireLower && !classes.lower && !classes.uncased {
+			add(CodePasswordMissingLower, "şifre en az bir küçük harf içermelidir", nil)
+		}
+		if p.RequireDigit && !classes.digit {
+			add(CodePasswordMissingDigit, "şifre en az bir rakam içermelidir", nil)
+		}
+		if p.RequireSymbol && !classes.symbol {
+			add(CodePasswordMissingSymbol, "şifre en az bir özel karakter içermelidir", nil)
+		}
+	}
+
+	if p.MaxRepeated > 0 && longestRun(password) > p.MaxRepeated {
+		add(CodePasswordRepeatedChars, fmt.Sprintf("şifrede aynı karakter arka arkaya en fazla %d kez kullanılabilir", p.MaxRepeated), map[string]interface{}{"max": p.MaxRepeated})
+	}
+
+	if word := p.bannedWord(password, userInputs); word != "" {
+		add(CodePasswordBannedWord, "şifre kullanıcı bilgilerini veya yasaklı kelimeleri içeremez", map[string]interface{}{"word": word})
+	}
+
+	if p.MinEntropyBits > 0 && EstimateEntropy(password) < p.MinEntropyBits {
+		add(CodePasswordTooWeak, "şifre tahmin edilmesi kolay, daha uzun veya daha çeşitli bir şifre seçin", map[string]interface{}{"min_entropy_bits": p.MinEntropyBits})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// bannedWord şifrede geçen ilk yasaklı kelimeyi döner; 3 karakterden kısa
+// kelimeler yanlış pozitif üreteceği için dikkate alınmaz
+func (p PasswordPolicy) bannedWord(password string, userInputs []string) string {
+	lowered := strings.ToLower(password)
+	for _, word := range append(append([]string{}, p.BannedWords...), userInputs...) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if utf8.RuneCountInString(word) >= 3 && strings.Contains(lowered, word) {
+			return word
+		}
+	}
+	return ""
+}
+
+// EstimateEntropy şifrenin kaba bir tahmin edilebilirlik tahminini bit
+// cinsinden verir: kullanılan karakter havuzunun büyüklüğüne göre karakter
+// başına bit, tekrar eden ve ardışık (abc, 321) karakterler yarım sayılır.
+func EstimateEntropy(password string) float64 {
+	classes := classify(password)
+	pool := 0
+	if classes.lower {
+		pool += 26
+	}
+	if classes.upper {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.symbol || classes.space {
+		pool += 33
+	}
+	if classes.uncased || classes.other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	effective := 0.0
+	var previous rune = -1
+	for _, char := range password {
+		delta := char - previous
+		if previous >= 0 && (delta == 0 || delta == 1 || delta == -1) {
+			effective += 0.5
+		} else {
+			effective++
+		}
+		previous = char
+	}
+
+	return effective * math.Log2(float64(pool))
+}
+
+type charClasses struct {
+	upper, lower, uncased, digit, symbol, space, other bool
+}
+
+func classify(password string) charClasses {
+	var classes charClasses
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			classes.upper = true
+		case unicode.IsLower(char):
+			classes.lower = true
+		case unicode.IsLetter(char):
+			// Büyük/küçük harf ayrımı olmayan yazı sistemleri (Arapça, CJK vb.)
+			classes.uncased = true
+		case unicode.IsNumber(char):
+			classes.digit = true
+		case unicode.IsSpace(char):
+			classes.space = true
+		case unicode.IsPunct(char), unicode.IsSymbol(char):
+			classes.symbol = true
+		default:
+			classes.other = true
+		}
+	}
+	return classes
+}
+
+func longestRun(password string) int {
+	longest, current := 0, 0
+	var previous rune = -1
+	for _, char := range password {
+		if char == previous {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+		previous = char
+	}
+	return longest
+}
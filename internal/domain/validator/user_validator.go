@@ -3,7 +3,6 @@ package validator
 import (
 	"errors"
 	"regexp"
-	"unicode"
 )
 
 var (
@@ -22,38 +21,7 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
+// ValidatePassword şifreyi varsayılan kurallara göre denetler
 func ValidatePassword(password string) error {
-	if len(password) < 8 {
-		return ErrPasswordTooShort
-	}
-
-	var (
-		hasUpper  bool
-		hasLower  bool
-		hasNumber bool
-		hasSpace  bool
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsSpace(char):
-			hasSpace = true
-		}
-	}
-
-	if hasSpace {
-		return ErrPasswordHasSpace
-	}
-
-	if !hasUpper || !hasLower || !hasNumber {
-		return ErrPasswordTooWeak
-	}
-
-	return nil
+	return DefaultPasswordPolicy.Validate(password)
 }
@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/domain/oauth"
+	"auth-service/internal/domain/validator"
 	"auth-service/internal/service"
+	"auth-service/pkg/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,11 +25,9 @@ func Register(authService *service.AuthService) fiber.Handler {
 			})
 		}
 
-		user, err := authService.Register(c.Context(), input)
+		user, err := authService.Register(c.UserContext(), input)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+			return passwordError(c, err)
 		}
 
 		return c.Status(fiber.StatusCreated).JSON(user)
@@ -37,17 +43,48 @@ func Login(authService *service.AuthService) fiber.Handler {
 			})
 		}
 
-		tokens, err := authService.Login(c.Context(), input)
+		result, err := authService.Login(c.UserContext(), input)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": err.Error(),
+			return loginError(c, err)
+		}
+
+		return c.JSON(result)
+	}
+}
+
+func Login2FA(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input service.Login2FAInput
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
 			})
 		}
 
+		tokens, err := authService.Login2FA(c.UserContext(), input)
+		if err != nil {
+			return loginError(c, err)
+		}
+
 		return c.JSON(tokens)
 	}
 }
 
+// loginError kilit hatalarında istemciye Retry-After ile 429, diğerlerinde 401 döner
+func loginError(c *fiber.Ctx, err error) error {
+	var lockoutErr *service.LockoutError
+	if errors.As(err, &lockoutErr) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockoutErr.RetryAfter().Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
 func RefreshToken(authService *service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		token := c.Query("token")
@@ -57,7 +94,7 @@ func RefreshToken(authService *service.AuthService) fiber.Handler {
 			})
 		}
 
-		tokens, err := authService.RefreshToken(c.Context(), token)
+		tokens, err := authService.RefreshToken(c.UserContext(), token)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": err.Error(),
@@ -78,7 +115,7 @@ func ForgotPassword(authService *service.AuthService) fiber.Handler {
 				"error": "Geçersiz istek formatı",
 			})
 		}
-		return authService.InitiatePasswordReset(c.Context(), input.Email)
+		return authService.InitiatePasswordReset(c.UserContext(), input.Email)
 	}
 }
 
@@ -93,7 +130,11 @@ func ResetPassword(authService *service.AuthService) fiber.Handler {
 				"error": "Geçersiz istek formatı",
 			})
 		}
-		return authService.ResetPassword(c.Context(), input.Token, input.NewPassword)
+		if err := authService.ResetPassword(c.UserContext(), input.Token, input.NewPassword); err != nil {
+			return passwordError(c, err)
+		}
+
+		return c.SendStatus(fiber.StatusOK)
 	}
 }
 
@@ -105,32 +146,197 @@ func VerifyEmail(authService *service.AuthService) fiber.Handler {
 				"error": "Token gerekli",
 			})
 		}
-		return authService.VerifyEmail(c.Context(), token)
+		if err := authService.VerifyEmail(c.UserContext(), token); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
 	}
 }
 
-func GoogleLogin(provider oauth.Provider) fiber.Handler {
+func ResendVerificationEmail(authService *service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		url := provider.GetAuthURL()
-		return c.Redirect(url)
+		var input struct {
+			Email string `json:"email"`
+		}
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		if err := authService.ResendVerificationEmail(c.UserContext(), input.Email); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusAccepted)
+	}
+}
+
+// oauthStateCookie sosyal girişi başlatan tarayıcıyı callback'e bağlar;
+// başkasının başlattığı akışın kurbana tamamlatılmasını (login CSRF) önler
+const oauthStateCookie = "oauth_state"
+
+// OAuthProviders istemcilerin giriş düğmelerini gösterebilmesi için etkin
+// sosyal giriş sağlayıcılarını listeler
+func OAuthProviders(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"providers": authService.OAuthProviders(),
+		})
+	}
+}
+
+func OAuthLogin(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		provider := c.Params("provider")
+		authURL, state, err := authService.BeginOAuthLogin(c.UserContext(), provider, c.Query("redirect_uri"))
+		if err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, service.ErrRedirectNotAllowed) {
+				status = fiber.StatusBadRequest
+			}
+			if errors.Is(err, service.ErrUnknownOAuthProvider) {
+				status = fiber.StatusNotFound
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		cookie := &fiber.Cookie{
+			Name:     oauthStateCookie,
+			Value:    state,
+			Path:     "/api/v1/auth",
+			MaxAge:   int(authService.OAuthStateTTL().Seconds()),
+			Secure:   c.Protocol() == "https",
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode, // Sağlayıcıdan dönen üst düzey GET isteğinde gönderilmeli
+		}
+		if authService.OAuthUsesFormPost(provider) {
+			// Sağlayıcının sayfasından gelen POST'ta Lax cookie gönderilmez
+			cookie.SameSite = fiber.CookieSameSiteNoneMode
+			cookie.Secure = true
+		}
+		c.Cookie(cookie)
+		return c.Redirect(authURL)
+	}
+}
+
+// callbackParam form_post yanıt modunda değerleri gövdeden, diğer
+// sağlayıcılarda sorgudan okur
+func callbackParam(c *fiber.Ctx, key string) string {
+	if c.Method() == fiber.MethodPost {
+		return c.FormValue(key)
 	}
+	return c.Query(key)
 }
 
-func GoogleCallback(authService *service.AuthService) fiber.Handler {
+func OAuthCallback(authService *service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		code := c.Query("code")
+		provider := c.Params("provider")
+		// Cookie tek kullanımlıktır
+		cookieState := c.Cookies(oauthStateCookie)
+		c.Cookie(&fiber.Cookie{
+			Name:     oauthStateCookie,
+			Path:     "/api/v1/auth", // Yol eşleşmezse tarayıcı cookie'yi silmez
+			Expires:  time.Now().Add(-time.Hour),
+			HTTPOnly: true,
+		})
+
+		if providerErr := callbackParam(c, "error"); providerErr != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Sağlayıcı girişi reddetti: " + providerErr,
+			})
+		}
+
+		code := callbackParam(c, "code")
 		if code == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Authorization code required",
 			})
 		}
-		tokens, err := authService.HandleOAuthCallback(c.Context(), code)
+
+		state := callbackParam(c, "state")
+		if state == "" || cookieState != state {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": service.ErrInvalidOAuthState.Error(),
+			})
+		}
+
+		result, redirectURI, err := authService.HandleOAuthCallback(c.UserContext(), provider, state, code)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": err.Error(),
+			if errors.Is(err, service.ErrUnknownOAuthProvider) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			if errors.Is(err, service.ErrInvalidOAuthState) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			if errors.Is(err, oauth.ErrInvalidIDToken) {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": oauth.ErrInvalidIDToken.Error(),
+				})
+			}
+			return loginError(c, err)
+		}
+
+		if redirectURI == "" {
+			return c.JSON(result)
+		}
+
+		// Token'lar sunucu loglarına düşmemesi için fragment ile iletilir
+		fragment := url.Values{}
+		if result.LinkRequired {
+			fragment.Set("link_required", "true")
+			fragment.Set("link_token", result.LinkToken)
+		} else if result.MFARequired {
+			fragment.Set("mfa_required", "true")
+			fragment.Set("mfa_token", result.MFAToken)
+		} else {
+			fragment.Set("access_token", result.AccessToken)
+			fragment.Set("refresh_token", result.RefreshToken)
+			if result.PasswordChangeRequired {
+				fragment.Set("password_change_required", "true")
+			}
+		}
+		// POST callback'ten sonra istemciye GET ile gidilmeli
+		return c.Redirect(redirectURI+"#"+fragment.Encode(), fiber.StatusSeeOther)
+	}
+}
+
+// LinkIdentity sosyal girişte emaili mevcut hesapla eşleşen sağlayıcı
+// hesabını, hesap şifresi doğrulandıktan sonra bağlar
+func LinkIdentity(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input struct {
+			LinkToken string `json:"link_token"`
+			Password  string `json:"password"`
+		}
+		if err := c.BodyParser(&input); err != nil || input.LinkToken == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
 			})
 		}
-		return c.JSON(tokens)
+
+		result, err := authService.LinkIdentity(c.UserContext(), input.LinkToken, input.Password)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidLinkToken) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			return loginError(c, err)
+		}
+
+		return c.JSON(result)
 	}
 }
 
@@ -139,7 +345,7 @@ func ListUsers(authService *service.AuthService) fiber.Handler {
 		offset := c.QueryInt("offset", 0)
 		limit := c.QueryInt("limit", 10)
 
-		users, err := authService.ListUsers(c.Context(), offset, limit)
+		users, err := authService.ListUsers(c.UserContext(), offset, limit)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -160,6 +366,84 @@ func ChangeUserRole(authService *service.AuthService) fiber.Handler {
 				"error": "Geçersiz istek formatı",
 			})
 		}
-		return authService.ChangeUserRole(c.Context(), userID, input.Role)
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		return authService.ChangeUserRole(c.UserContext(), userID, input.Role, adminID)
+	}
+}
+
+// RequirePasswordChange seçilen kullanıcıları toplu olarak şifre değiştirmeye zorlar
+func RequirePasswordChange(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input struct {
+			UserIDs []string `json:"user_ids"`
+			Reason  string   `json:"reason"`
+		}
+		if err := c.BodyParser(&input); err != nil || len(input.UserIDs) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		result, err := authService.RequirePasswordChange(c.UserContext(), input.UserIDs, input.Reason, adminID)
+		if err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, service.ErrTooManyUsers) {
+				status = fiber.StatusBadRequest
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(result)
+	}
+}
+
+// PasswordPolicy istemcilerin şifre kurallarını gösterebilmesi için geçerli politikayı döner
+func PasswordPolicy(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(authService.PasswordPolicy())
+	}
+}
+
+// passwordError şifre kuralı ihlallerini istemcinin yerelleştirebileceği
+// kodlarla, diğer hataları düz mesaj olarak 400 ile döner
+func passwordError(c *fiber.Ctx, err error) error {
+	var policyErr *validator.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":      err.Error(),
+			"violations": policyErr.Violations,
+		})
+	}
+	code := ""
+	switch {
+	case errors.Is(err, service.ErrPasswordBreached):
+		code = validator.CodePasswordBreached
+	case errors.Is(err, service.ErrPasswordReused):
+		code = validator.CodePasswordReused
+	}
+	if code != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+			"violations": []validator.PolicyViolation{
+				{Code: code, Message: err.Error()},
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// JWKS access token'ları doğrulayan açık anahtarları yayınlar. Aynı anahtarlar
+// kısıtlı kapsamlı token'ları da imzaladığından doğrulayan servisler typ=at+jwt,
+// iss ve aud değerlerini de kontrol etmelidir.
+func JWKS(jwtManager *security.JWTManager) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(jwtManager.JWKS())
 	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/service"
+	"auth-service/pkg/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ListSigningKeys(keyService *service.KeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keys, err := keyService.ListKeys(c.UserContext())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(keys)
+	}
+}
+
+func RotateSigningKey(keyService *service.KeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input struct {
+			Purpose security.KeyPurpose `json:"purpose"`
+		}
+
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		key, err := keyService.Rotate(c.UserContext(), input.Purpose, adminID)
+		if errors.Is(err, service.ErrInvalidKeyPurpose) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(key)
+	}
+}
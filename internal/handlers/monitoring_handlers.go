@@ -8,7 +8,7 @@ import (
 
 func GetMetrics(monitoringService *service.MonitoringService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		metrics, err := monitoringService.GetMetrics(c.Context())
+		metrics, err := monitoringService.GetMetrics(c.UserContext())
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -21,7 +21,7 @@ func GetMetrics(monitoringService *service.MonitoringService) fiber.Handler {
 
 func GetActiveUsers(monitoringService *service.MonitoringService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		count, err := monitoringService.GetActiveUsersCount(c.Context())
+		count, err := monitoringService.GetActiveUsersCount(c.UserContext())
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -36,7 +36,7 @@ func GetActiveUsers(monitoringService *service.MonitoringService) fiber.Handler
 
 func GetBlockedUsers(monitoringService *service.MonitoringService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		count, err := monitoringService.GetBlockedUsersCount(c.Context())
+		count, err := monitoringService.GetBlockedUsersCount(c.UserContext())
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Introspect RFC 7662 token introspection ucu
+func Introspect(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client, err := authenticateClient(c, oauthService)
+		if err != nil {
+			return clientAuthError(c, err)
+		}
+
+		token := c.FormValue("token")
+		if token == "" {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_request", "token parametresi eksik")
+		}
+
+		response, err := oauthService.Introspect(c.UserContext(), client, token, c.FormValue("token_type_hint"))
+		if err != nil {
+			return oauthError(c, fiber.StatusInternalServerError, "server_error", err.Error())
+		}
+
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(response)
+	}
+}
+
+// RevokeToken RFC 7009 token iptal ucu; bilinmeyen token'lar için de 200 döner
+func RevokeToken(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client, err := authenticateClient(c, oauthService)
+		if err != nil {
+			return clientAuthError(c, err)
+		}
+
+		token := c.FormValue("token")
+		if token == "" {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_request", "token parametresi eksik")
+		}
+
+		err = oauthService.Revoke(c.UserContext(), client, token, c.FormValue("token_type_hint"))
+		if errors.Is(err, service.ErrTokenNotOwned) {
+			return oauthError(c, fiber.StatusBadRequest, "unauthorized_client", err.Error())
+		}
+		if err != nil {
+			return oauthError(c, fiber.StatusServiceUnavailable, "server_error", err.Error())
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func CreateOAuthClient(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input service.OAuthClientInput
+		if err := c.BodyParser(&input); err != nil || strings.TrimSpace(input.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+		input.Name = strings.TrimSpace(input.Name)
+
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		client, secret, err := oauthService.CreateClient(c.UserContext(), input, adminID)
+		if errors.Is(err, service.ErrInvalidRedirectURI) || errors.Is(err, service.ErrInvalidScope) || errors.Is(err, service.ErrPublicResourceServer) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		// Secret yalnızca oluşturma yanıtında gösterilir
+		response := fiber.Map{
+			"client_id":       client.ID,
+			"name":            client.Name,
+			"public":          client.IsPublic,
+			"redirect_uris":   client.RedirectURIs,
+			"allowed_scopes":  client.AllowedScopes,
+			"resource_server": client.IsResourceServer,
+		}
+		if secret != "" {
+			response["client_secret"] = secret
+		}
+		return c.Status(fiber.StatusCreated).JSON(response)
+	}
+}
+
+func ListOAuthClients(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		clients, err := oauthService.ListClients(c.UserContext())
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(clients)
+	}
+}
+
+func DeactivateOAuthClient(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := oauthService.DeactivateClient(c.UserContext(), c.Params("id"))
+		if errors.Is(err, service.ErrClientNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+// Authorize /oauth/authorize ucu; istemciyi ve yönlendirme adresini doğrulayıp
+// tarayıcıyı giriş arayüzüne yönlendirir. Giriş yapan arayüz aynı
+// parametrelerle AuthorizeGrant'i çağırır.
+func Authorize(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := authorizeRequestFromQuery(c)
+		if _, _, err := oauthService.ValidateAuthorizeRequest(c.UserContext(), req); err != nil {
+			return authorizeError(c, req, err)
+		}
+
+		loginURL, err := oauthService.LoginRedirect(string(c.Request().URI().QueryString()))
+		if err != nil {
+			return oauthError(c, fiber.StatusServiceUnavailable, "temporarily_unavailable", err.Error())
+		}
+		return c.Redirect(loginURL)
+	}
+}
+
+// AuthorizeGrant giriş yapmış kullanıcı adına authorization code üretir ve
+// arayüzün tarayıcıyı yönlendireceği adresi döner
+func AuthorizeGrant(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req service.AuthorizeRequest
+		if err := c.BodyParser(&req); err != nil {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_request", "Geçersiz istek formatı")
+		}
+
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		redirectURL, err := oauthService.Authorize(c.UserContext(), userID, req)
+		if err != nil {
+			if errors.Is(err, service.ErrUserBlocked) || errors.Is(err, service.ErrUserNotFound) {
+				return oauthError(c, fiber.StatusForbidden, "access_denied", err.Error())
+			}
+			return authorizeError(c, req, err)
+		}
+
+		return c.JSON(fiber.Map{
+			"redirect_uri": redirectURL,
+		})
+	}
+}
+
+// Token /oauth/token ucu: authorization_code ve refresh_token grant'leri
+func Token(oauthService *service.OAuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+
+		clientID, secret, err := clientCredentials(c)
+		if err != nil {
+			return clientAuthError(c, err)
+		}
+		client, err := oauthService.AuthenticateTokenClient(c.UserContext(), clientID, secret)
+		if err != nil {
+			return clientAuthError(c, err)
+		}
+
+		var response *entity.OAuthTokenResponse
+		switch c.FormValue("grant_type") {
+		case "authorization_code":
+			response, err = oauthService.ExchangeAuthorizationCode(c.UserContext(), client,
+				c.FormValue("code"), c.FormValue("redirect_uri"), c.FormValue("code_verifier"))
+		case "refresh_token":
+			response, err = oauthService.RefreshClientToken(c.UserContext(), client,
+				c.FormValue("refresh_token"), c.FormValue("scope"))
+		default:
+			return oauthError(c, fiber.StatusBadRequest, "unsupported_grant_type", "desteklenmeyen grant_type")
+		}
+
+		if errors.Is(err, service.ErrInvalidGrant) {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_grant", err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidScope) {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_scope", err.Error())
+		}
+		if err != nil {
+			return oauthError(c, fiber.StatusInternalServerError, "server_error", err.Error())
+		}
+
+		return c.JSON(response)
+	}
+}
+
+func authorizeRequestFromQuery(c *fiber.Ctx) service.AuthorizeRequest {
+	return service.AuthorizeRequest{
+		ResponseType:        c.Query("response_type"),
+		ClientID:            c.Query("client_id"),
+		RedirectURI:         c.Query("redirect_uri"),
+		Scope:               c.Query("scope"),
+		State:               c.Query("state"),
+		CodeChallenge:       c.Query("code_challenge"),
+		CodeChallengeMethod: c.Query("code_challenge_method"),
+	}
+}
+
+// authorizeError RFC 6749 4.1.2.1: istemci veya yönlendirme adresi
+// doğrulanamadıysa hata doğrudan gösterilir, aksi halde istemciye iletilir
+func authorizeError(c *fiber.Ctx, req service.AuthorizeRequest, err error) error {
+	if errors.Is(err, service.ErrInvalidClient) {
+		return oauthError(c, fiber.StatusBadRequest, "invalid_client", err.Error())
+	}
+	if errors.Is(err, service.ErrInvalidRedirectURI) {
+		return oauthError(c, fiber.StatusBadRequest, "invalid_request", err.Error())
+	}
+
+	code := "server_error"
+	if errors.Is(err, service.ErrUnsupportedResponseType) {
+		code = "unsupported_response_type"
+	}
+	if errors.Is(err, service.ErrPKCERequired) {
+		code = "invalid_request"
+	}
+	if errors.Is(err, service.ErrInvalidScope) {
+		code = "invalid_scope"
+	}
+
+	params := url.Values{}
+	params.Set("error", code)
+	params.Set("error_description", err.Error())
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	redirectURL := service.AppendQuery(req.RedirectURI, params)
+
+	// Giriş arayüzünden gelen çağrıda yönlendirmeyi arayüz yapar
+	if c.Method() == fiber.MethodPost {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":             code,
+			"error_description": err.Error(),
+			"redirect_uri":      redirectURL,
+		})
+	}
+	return c.Redirect(redirectURL)
+}
+
+// authenticateClient istemciyi HTTP Basic (client_secret_basic) ya da form
+// alanları (client_secret_post) ile doğrular
+func authenticateClient(c *fiber.Ctx, oauthService *service.OAuthService) (*entity.OAuthClient, error) {
+	clientID, secret, err := clientCredentials(c)
+	if err != nil {
+		return nil, err
+	}
+	return oauthService.AuthenticateClient(c.UserContext(), clientID, secret)
+}
+
+// clientCredentials istemci kimlik bilgilerini Basic başlıktan veya form alanlarından okur
+func clientCredentials(c *fiber.Ctx) (string, string, error) {
+	clientID, secret := c.FormValue("client_id"), c.FormValue("client_secret")
+
+	if header := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(header, "Basic ") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Basic "))
+		if err != nil {
+			return "", "", service.ErrInvalidClient
+		}
+		id, pass, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return "", "", service.ErrInvalidClient
+		}
+		// RFC 6749 2.3.1: kimlik bilgileri form-urlencoded olarak kodlanır
+		if clientID, err = url.QueryUnescape(id); err != nil {
+			return "", "", service.ErrInvalidClient
+		}
+		if secret, err = url.QueryUnescape(pass); err != nil {
+			return "", "", service.ErrInvalidClient
+		}
+	}
+
+	return clientID, secret, nil
+}
+
+func clientAuthError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidClient) {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+		return oauthError(c, fiber.StatusUnauthorized, "invalid_client", err.Error())
+	}
+	return oauthError(c, fiber.StatusInternalServerError, "server_error", err.Error())
+}
+
+// oauthError RFC 6749 5.2 biçiminde hata yanıtı döner
+func oauthError(c *fiber.Ctx, status int, code, description string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"error":             code,
+		"error_description": description,
+	})
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"time"
+
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/service"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,7 +24,13 @@ func BlockUser(securityService *service.SecurityService) fiber.Handler {
 		}
 
 		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
-		if err := securityService.BlockUser(c.Context(), userID, adminID, input.Reason); err != nil {
+		err := securityService.BlockUser(c.UserContext(), userID, adminID, input.Reason)
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
 			})
@@ -37,7 +45,13 @@ func UnblockUser(securityService *service.SecurityService) fiber.Handler {
 		userID := c.Params("id")
 		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
 
-		if err := securityService.UnblockUser(c.Context(), userID, adminID); err != nil {
+		err := securityService.UnblockUser(c.UserContext(), userID, adminID)
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
 			})
@@ -52,7 +66,7 @@ func GetSecurityAlerts(securityService *service.SecurityService) fiber.Handler {
 		from := c.QueryTime("from", time.Now().AddDate(0, 0, -7))
 		to := c.QueryTime("to", time.Now())
 
-		alerts, err := securityService.GetSecurityAlerts(c.Context(), from, to)
+		alerts, err := securityService.GetSecurityAlerts(c.UserContext(), from, to)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -66,7 +80,7 @@ func GetSecurityAlerts(securityService *service.SecurityService) fiber.Handler {
 func GetSuspiciousActivities(securityService *service.SecurityService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		threshold := c.QueryInt("threshold", 5)
-		activities, err := securityService.GetSuspiciousActivities(c.Context(), threshold)
+		activities, err := securityService.GetSuspiciousActivities(c.UserContext(), threshold)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
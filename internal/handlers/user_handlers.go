@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/service"
 
@@ -21,10 +23,8 @@ func ChangePassword(authService *service.AuthService) fiber.Handler {
 		}
 
 		userID := c.Locals("claims").(*entity.TokenClaims).UserID
-		if err := authService.ChangePassword(c.Context(), userID, input.OldPassword, input.NewPassword); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": err.Error(),
-			})
+		if err := authService.ChangePassword(c.UserContext(), userID, input.OldPassword, input.NewPassword); err != nil {
+			return passwordError(c, err)
 		}
 
 		return c.SendStatus(fiber.StatusOK)
@@ -33,8 +33,25 @@ func ChangePassword(authService *service.AuthService) fiber.Handler {
 
 func Enable2FA(authService *service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Yeniden kayıtta mevcut kimlik doğrulayıcıdan bir kod gerekir
+		var input struct {
+			Code string `json:"code"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&input); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Geçersiz istek formatı",
+				})
+			}
+		}
+
 		userID := c.Locals("claims").(*entity.TokenClaims).UserID
-		secret, qrCode, err := authService.Enable2FA(c.Context(), userID)
+		secret, qrCode, err := authService.Enable2FA(c.UserContext(), userID, input.Code)
+		if errors.Is(err, service.ErrInvalid2FACode) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
@@ -61,12 +78,227 @@ func Verify2FA(authService *service.AuthService) fiber.Handler {
 		}
 
 		userID := c.Locals("claims").(*entity.TokenClaims).UserID
-		if err := authService.Verify2FA(c.Context(), userID, input.Code); err != nil {
+		recoveryCodes, err := authService.Verify2FA(c.UserContext(), userID, input.Code)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"recovery_codes": recoveryCodes,
+		})
+	}
+}
+
+func Disable2FA(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input struct {
+			Password string `json:"password"`
+			Code     string `json:"code"`
+		}
+
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		if err := authService.Disable2FA(c.UserContext(), userID, input.Password, input.Code); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func Reset2FA(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Params("id")
+		var input struct {
+			Reason string `json:"reason"`
+		}
+
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		if err := authService.Reset2FA(c.UserContext(), userID, adminID, input.Reason); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func RegenerateRecoveryCodes(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var input struct {
+			Code string `json:"code"`
+		}
+
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Geçersiz istek formatı",
+			})
+		}
+
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		recoveryCodes, err := authService.RegenerateRecoveryCodes(c.UserContext(), userID, input.Code)
+		var lockoutErr *service.LockoutError
+		if errors.As(err, &lockoutErr) {
+			return loginError(c, err)
+		}
+		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
 
+		return c.JSON(fiber.Map{
+			"recovery_codes": recoveryCodes,
+		})
+	}
+}
+
+func Logout(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := c.Locals("claims").(*entity.TokenClaims)
+		if err := authService.Logout(c.UserContext(), claims); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func LogoutAll(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Varsayılan olarak mevcut oturum da dahil tüm oturumlar kapatılır
+		var input struct {
+			KeepCurrent bool `json:"keep_current"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&input); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Geçersiz istek formatı",
+				})
+			}
+		}
+
+		claims := c.Locals("claims").(*entity.TokenClaims)
+		if err := authService.LogoutAll(c.UserContext(), claims, input.KeepCurrent); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func GetSessions(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := c.Locals("claims").(*entity.TokenClaims)
+		sessions, err := authService.ListSessions(c.UserContext(), claims.UserID, claims.SessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(sessions)
+	}
+}
+
+func RevokeSession(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		return revokeSession(c, authService, userID, userID)
+	}
+}
+
+func GetUserSessions(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessions, err := authService.ListSessions(c.UserContext(), c.Params("id"), "")
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(sessions)
+	}
+}
+
+func RevokeUserSession(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		adminID := c.Locals("claims").(*entity.TokenClaims).UserID
+		return revokeSession(c, authService, c.Params("id"), adminID)
+	}
+}
+
+// revokeSession kullanıcı ve yönetici oturum kapatma uçlarının ortak gövdesi
+func revokeSession(c *fiber.Ctx, authService *service.AuthService, userID, revokedBy string) error {
+	err := authService.RevokeSession(c.UserContext(), userID, c.Params("sid"), revokedBy)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func GetIdentities(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		identities, err := authService.ListIdentities(c.UserContext(), userID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(identities)
+	}
+}
+
+func UnlinkIdentity(authService *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := c.Locals("claims").(*entity.TokenClaims).UserID
+		err := authService.UnlinkIdentity(c.UserContext(), userID, c.Params("id"))
+		if errors.Is(err, service.ErrIdentityNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrLastLoginMethod) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
 		return c.SendStatus(fiber.StatusOK)
 	}
 }
@@ -77,7 +309,7 @@ func GetAuditLogs(authService *service.AuthService) fiber.Handler {
 		limit := c.QueryInt("limit", 10)
 		offset := c.QueryInt("offset", 0)
 
-		logs, err := authService.GetAuditLogs(c.Context(), userID, limit, offset)
+		logs, err := authService.GetAuditLogs(c.UserContext(), userID, limit, offset)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": err.Error(),
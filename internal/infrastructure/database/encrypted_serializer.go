@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"auth-service/pkg/security"
+
+	"gorm.io/gorm/schema"
+)
+
+// EncryptedSerializer `serializer:encrypted` etiketli string alanları
+// veritabanına yazarken şifreler, okurken çözer. Şifreli değer tablo, kolon ve
+// satırın birincil anahtarına bağlanır; başka satıra veya kolona kopyalanan
+// değer çözülemez. Bu yüzden birincil anahtar şifreli alanla birlikte (ve
+// ondan önce) seçilmelidir. Şifrelenmemiş eski değerler olduğu gibi okunur;
+// cmd/reencrypt ile şifrelenir. []byte alanlar (ör. imza anahtarları) base64
+// metin olarak şifrelenir ve text kolonda saklanır.
+type EncryptedSerializer struct {
+	envelope *security.Envelope
+}
+
+// RegisterEncryptedSerializer serializer'ı GORM'a kaydeder; şema ayrıştırılmadan
+// (ilk sorgu veya migrasyondan) önce çağrılmalıdır
+func RegisterEncryptedSerializer(envelope *security.Envelope) {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{envelope: envelope})
+}
+
+func (s EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch value := dbValue.(type) {
+	case nil:
+	case string:
+		stored = value
+	case []byte:
+		stored = string(value)
+	default:
+		return fmt.Errorf("%s alanı için desteklenmeyen değer tipi %T", field.Name, dbValue)
+	}
+
+	plaintext := stored
+	if security.IsEncrypted(stored) {
+		aad, err := associatedData(ctx, field, dst)
+		if err != nil {
+			return err
+		}
+		if plaintext, err = s.envelope.Decrypt(stored, aad); err != nil {
+			return fmt.Errorf("%s alanı çözülemedi: %w", field.Name, err)
+		}
+	}
+
+	if field.FieldType.Kind() == reflect.Slice {
+		data, err := base64.StdEncoding.DecodeString(plaintext)
+		if err != nil {
+			return fmt.Errorf("%s alanı base64 değil: %w", field.Name, err)
+		}
+		return field.Set(ctx, dst, data)
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+func (s EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch value := fieldValue.(type) {
+	case string:
+		plaintext = value
+	case []byte:
+		plaintext = base64.StdEncoding.EncodeToString(value)
+	default:
+		return nil, fmt.Errorf("%s alanı string veya []byte olmalıdır", field.Name)
+	}
+	if plaintext == "" {
+		return "", nil
+	}
+	aad, err := associatedData(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	return s.envelope.Encrypt(plaintext, aad)
+}
+
+// associatedData alanın ait olduğu satırı tablo, kolon ve birincil anahtarla tanımlar
+func associatedData(ctx context.Context, field *schema.Field, dst reflect.Value) ([]byte, error) {
+	primaryField := field.Schema.PrioritizedPrimaryField
+	if primaryField == nil {
+		return nil, fmt.Errorf("%s alanının tablosunda birincil anahtar yok", field.Name)
+	}
+	id, isZero := primaryField.ValueOf(ctx, dst)
+	if isZero {
+		return nil, fmt.Errorf("%s alanı için birincil anahtar değeri yok", field.Name)
+	}
+	return security.AssociatedData(field.Schema.Table, field.DBName, fmt.Sprint(id)), nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"auth-service/internal/config"
 	"auth-service/internal/domain/entity"
+	"auth-service/pkg/security"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -14,7 +15,10 @@ type PostgresDB struct {
 	db *gorm.DB
 }
 
-func NewPostgresConnection(cfg config.DatabaseConfig) (*PostgresDB, error) {
+func NewPostgresConnection(cfg config.DatabaseConfig, envelope *security.Envelope) (*PostgresDB, error) {
+	// Şifreli kolonlar şema ayrıştırılmadan önce serializer'a ihtiyaç duyar
+	RegisterEncryptedSerializer(envelope)
+
 	dsn := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host,
@@ -34,6 +38,13 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*PostgresDB, error) {
 	err = db.AutoMigrate(
 		&entity.User{},
 		&entity.Subscription{},
+		&entity.AuditLog{},
+		&entity.RecoveryCode{},
+		&entity.SigningKey{},
+		&entity.OAuthClient{},
+		&entity.PasswordHistory{},
+		&entity.UserToken{},
+		&entity.UserIdentity{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("migrasyon hatası: %v", err)
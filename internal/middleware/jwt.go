@@ -4,12 +4,13 @@ import (
 	"strings"
 
 	"auth-service/internal/domain/entity"
+	"auth-service/internal/service"
 	"auth-service/pkg/security"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func JWTAuth(jwtManager *security.JWTManager) fiber.Handler {
+func JWTAuth(jwtManager *security.JWTManager, securityService *service.SecurityService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Authorization header'ı al
 		authHeader := c.Get("Authorization")
@@ -35,6 +36,27 @@ func JWTAuth(jwtManager *security.JWTManager) fiber.Handler {
 			})
 		}
 
+		// İptal edilmiş token'ları reddet
+		revoked, err := securityService.IsTokenRevoked(c.UserContext(), claims)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "token durumu kontrol edilemedi",
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "token iptal edilmiş",
+			})
+		}
+
+		// OAuth istemcilerine verilen token'lar kullanıcı adına diğer servisler
+		// içindir; hesap yönetimi uçlarında kabul edilmez
+		if claims.ClientID != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "bu token bu API için geçerli değil",
+			})
+		}
+
 		// Claims'i context'e ekle
 		c.Locals("claims", claims)
 		return c.Next()
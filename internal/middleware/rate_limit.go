@@ -9,9 +9,19 @@ import (
 )
 
 func RateLimit(redisClient *redis.Client, maxRequests int, window time.Duration) fiber.Handler {
+	return rateLimit(redisClient, "rate_limit:", maxRequests, window)
+}
+
+// RouteRateLimit genel limitten bağımsız, isimlendirilmiş bir sayaçla tek bir
+// rotayı (ör. email gönderen uç noktalar) sınırlar
+func RouteRateLimit(redisClient *redis.Client, name string, maxRequests int, window time.Duration) fiber.Handler {
+	return rateLimit(redisClient, "rate_limit:"+name+":", maxRequests, window)
+}
+
+func rateLimit(redisClient *redis.Client, prefix string, maxRequests int, window time.Duration) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ip := c.IP()
-		key := "rate_limit:" + ip
+		key := prefix + ip
 
 		ctx := context.Background()
 		pipe := redisClient.Pipeline()
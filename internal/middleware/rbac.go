@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"auth-service/internal/domain/entity"
 
 	"github.com/gofiber/fiber/v2"
@@ -26,3 +28,52 @@ func RequireRole(roles ...entity.Role) fiber.Handler {
 		})
 	}
 }
+
+func RequirePermission(permissions ...entity.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(*entity.TokenClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "yetkilendirme başarısız",
+			})
+		}
+
+		for _, permission := range permissions {
+			if claims.Role.HasPermission(permission) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "bu işlem için yetkiniz yok",
+		})
+	}
+}
+
+// RestrictScope verilen kısıtlı kapsamdaki token'ların yalnızca izin verilen
+// yol öneklerine erişmesine izin verir; diğer token'lar etkilenmez. Önekler
+// tam yol bölümleriyle eşleşir: /user, /users'ı kapsamaz.
+func RestrictScope(scope string, allowedPrefixes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(*entity.TokenClaims)
+		if !ok || claims.Scope != scope {
+			return c.Next()
+		}
+
+		for _, prefix := range allowedPrefixes {
+			if pathHasPrefix(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "token kapsamı bu işlem için yetersiz",
+		})
+	}
+}
+
+// pathHasPrefix yolun öneğin kendisi veya altındaki bir yol olduğunu kontrol eder
+func pathHasPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
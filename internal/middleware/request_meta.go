@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"auth-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestMeta istemci IP'si ve User-Agent bilgisini servislerin denetim
+// kayıtlarında kullanabilmesi için c.UserContext()'e ekler
+func RequestMeta() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(service.WithRequestMeta(c.UserContext(), service.RequestMeta{
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}))
+		return c.Next()
+	}
+}
@@ -24,7 +24,12 @@ func (r *GormAuditRepository) Create(ctx context.Context, log *entity.AuditLog)
 
 func (r *GormAuditRepository) GetByUserID(ctx context.Context, userID string, limit, offset int) ([]entity.AuditLog, error) {
 	var logs []entity.AuditLog
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Limit(limit).Offset(offset).Find(&logs).Error
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error
 	return logs, err
 }
 
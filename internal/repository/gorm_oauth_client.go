@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type GormOAuthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) repository.OAuthClientRepository {
+	return &GormOAuthClientRepository{db: db}
+}
+
+func (r *GormOAuthClientRepository) Create(ctx context.Context, client *entity.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+func (r *GormOAuthClientRepository) GetByID(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	var client entity.OAuthClient
+	if err := r.db.WithContext(ctx).First(&client, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *GormOAuthClientRepository) List(ctx context.Context) ([]entity.OAuthClient, error) {
+	var clients []entity.OAuthClient
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&clients).Error
+	return clients, err
+}
+
+func (r *GormOAuthClientRepository) Update(ctx context.Context, client *entity.OAuthClient) error {
+	return r.db.WithContext(ctx).Save(client).Error
+}
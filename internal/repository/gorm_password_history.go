@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type GormPasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) repository.PasswordHistoryRepository {
+	return &GormPasswordHistoryRepository{db: db}
+}
+
+func (r *GormPasswordHistoryRepository) Add(ctx context.Context, entry *entity.PasswordHistory, keep int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		// En yeni keep kayıt dışındakileri temizle
+		recent := tx.Model(&entity.PasswordHistory{}).
+			Select("id").
+			Where("user_id = ?", entry.UserID).
+			Order("created_at DESC").
+			Limit(keep)
+		return tx.Where("user_id = ? AND id NOT IN (?)", entry.UserID, recent).
+			Delete(&entity.PasswordHistory{}).Error
+	})
+}
+
+func (r *GormPasswordHistoryRepository) ListRecent(ctx context.Context, userID string, limit int) ([]entity.PasswordHistory, error) {
+	var entries []entity.PasswordHistory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
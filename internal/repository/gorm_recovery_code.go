@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type GormRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) repository.RecoveryCodeRepository {
+	return &GormRecoveryCodeRepository{db: db}
+}
+
+func (r *GormRecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID string, codes []entity.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *GormRecoveryCodeRepository) Consume(ctx context.Context, userID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormRecoveryCodeRepository) CountUnused(ctx context.Context, userID string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return int(count), err
+}
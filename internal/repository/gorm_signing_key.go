@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) repository.SigningKeyRepository {
+	return &GormSigningKeyRepository{db: db}
+}
+
+func (r *GormSigningKeyRepository) Create(ctx context.Context, key *entity.SigningKey) error {
+	// Birden fazla instance aynı başlangıç anahtarını eklemeye çalışabilir
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key).Error
+}
+
+func (r *GormSigningKeyRepository) ListUsable(ctx context.Context, now time.Time) ([]entity.SigningKey, error) {
+	var keys []entity.SigningKey
+	err := r.db.WithContext(ctx).
+		Where("retires_at IS NULL OR retires_at > ?", now).
+		Order("activates_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormSigningKeyRepository) Rotate(ctx context.Context, purpose string, retiresAt time.Time, key *entity.SigningKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.SigningKey{}).
+			Where("purpose = ? AND retires_at IS NULL", purpose).
+			Update("retires_at", retiresAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/domain/repository"
@@ -44,19 +45,44 @@ func (r *GormUserRepository) GetByEmail(ctx context.Context, email string) (*ent
 	return &user, nil
 }
 
-func (r *GormUserRepository) GetByResetToken(ctx context.Context, token string) (*entity.User, error) {
-	var user entity.User
-	if err := r.db.WithContext(ctx).First(&user, "password_reset_token = ?", token).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, err
+func (r *GormUserRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *GormUserRepository) UpdateFields(ctx context.Context, user *entity.User, fields ...string) error {
+	// Select ile sıfır değerler de yazılır; şifreli alanlar serializer'dan geçer
+	return r.db.WithContext(ctx).Model(user).Select(fields).Updates(user).Error
+}
+
+func (r *GormUserRepository) IncrementFailedLogins(ctx context.Context, id string, at time.Time, threshold int, lockedUntil time.Time) (*entity.LoginFailureState, error) {
+	// SET ifadeleri satırın eski değerini gördüğünden eşik kontrolü
+	// failed_login_attempts + 1 ile yapılır
+	var state entity.LoginFailureState
+	result := r.db.WithContext(ctx).Raw(`
+		UPDATE users SET
+			failed_login_attempts = failed_login_attempts + 1,
+			last_failed_login_at = ?,
+			locked_until = CASE WHEN ? > 0 AND failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END
+		WHERE id = ? AND deleted_at IS NULL
+		RETURNING failed_login_attempts, last_failed_login_at, locked_until`,
+		at, threshold, threshold, lockedUntil, id).Scan(&state)
+	if result.Error != nil {
+		return nil, result.Error
 	}
-	return &user, nil
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &state, nil
 }
 
-func (r *GormUserRepository) Update(ctx context.Context, user *entity.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+func (r *GormUserRepository) RecordSuccessfulLogin(ctx context.Context, id string, at time.Time, ip string) error {
+	return r.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+		"last_login_at":         at,
+		"last_login_ip":         ip,
+	}).Error
 }
 
 func (r *GormUserRepository) List(ctx context.Context, offset, limit int) ([]entity.User, error) {
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type GormUserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) repository.UserIdentityRepository {
+	return &GormUserIdentityRepository{db: db}
+}
+
+func (r *GormUserIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *GormUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *GormUserIdentityRepository) ListByUser(ctx context.Context, userID string) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&identities).Error
+	return identities, err
+}
+
+func (r *GormUserIdentityRepository) Delete(ctx context.Context, userID, id string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&entity.UserIdentity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormUserIdentityRepository) DeleteAllForUser(ctx context.Context, userID string) (int, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&entity.UserIdentity{})
+	return int(result.RowsAffected), result.Error
+}
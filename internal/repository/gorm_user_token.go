@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type GormUserTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return &GormUserTokenRepository{db: db}
+}
+
+func (r *GormUserTokenRepository) Replace(ctx context.Context, token *entity.UserToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ?", token.UserID, token.Purpose).
+			Delete(&entity.UserToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *GormUserTokenRepository) GetValid(ctx context.Context, tokenHash string, purpose entity.TokenPurpose) (*entity.UserToken, error) {
+	var token entity.UserToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, time.Now()).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GormUserTokenRepository) Consume(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entity.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormUserTokenRepository) DeleteForUser(ctx context.Context, userID string, purpose entity.TokenPurpose) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(&entity.UserToken{}).Error
+}
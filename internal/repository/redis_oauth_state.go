@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+	"auth-service/pkg/security"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisOAuthStateRepository struct {
+	client *redis.Client
+}
+
+func NewOAuthStateRepository(client *redis.Client) repository.OAuthStateRepository {
+	return &RedisOAuthStateRepository{client: client}
+}
+
+func (r *RedisOAuthStateRepository) Save(ctx context.Context, state string, data *entity.OAuthState, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, oauthStateKey(state), payload, ttl).Err()
+}
+
+func (r *RedisOAuthStateRepository) Consume(ctx context.Context, state string) (*entity.OAuthState, error) {
+	// GETDEL aynı state'in ikinci kez kullanılmasını engeller
+	payload, err := r.client.GetDel(ctx, oauthStateKey(state)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var data entity.OAuthState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func oauthStateKey(state string) string {
+	return "oauth_state:" + state
+}
+
+type RedisOAuthLinkRepository struct {
+	client *redis.Client
+}
+
+func NewOAuthLinkRepository(client *redis.Client) repository.OAuthLinkRepository {
+	return &RedisOAuthLinkRepository{client: client}
+}
+
+func (r *RedisOAuthLinkRepository) Save(ctx context.Context, token string, link *entity.PendingIdentityLink, ttl time.Duration) error {
+	payload, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, oauthLinkKey(token), payload, ttl).Err()
+}
+
+func (r *RedisOAuthLinkRepository) Get(ctx context.Context, token string) (*entity.PendingIdentityLink, error) {
+	payload, err := r.client.Get(ctx, oauthLinkKey(token)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var link entity.PendingIdentityLink
+	if err := json.Unmarshal(payload, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *RedisOAuthLinkRepository) Delete(ctx context.Context, token string) error {
+	return r.client.Del(ctx, oauthLinkKey(token)).Err()
+}
+
+func oauthLinkKey(token string) string {
+	return "oauth_link:" + token
+}
+
+type RedisAuthorizationCodeRepository struct {
+	client *redis.Client
+}
+
+func NewAuthorizationCodeRepository(client *redis.Client) repository.AuthorizationCodeRepository {
+	return &RedisAuthorizationCodeRepository{client: client}
+}
+
+func (r *RedisAuthorizationCodeRepository) Save(ctx context.Context, code string, data *entity.AuthorizationCode, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, authorizationCodeKey(code), payload, ttl).Err()
+}
+
+func (r *RedisAuthorizationCodeRepository) Consume(ctx context.Context, code string) (*entity.AuthorizationCode, error) {
+	// GETDEL aynı code'un ikinci kez token'a çevrilmesini engeller
+	payload, err := r.client.GetDel(ctx, authorizationCodeKey(code)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var data entity.AuthorizationCode
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// authorizationCodeKey code'u özetleyerek saklar; Redis dökümünden kullanılabilir code okunamaz
+func authorizationCodeKey(code string) string {
+	return "oauth_code:" + security.HashToken(code)
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
 	"auth-service/internal/domain/entity"
@@ -19,12 +21,95 @@ func NewSessionRepository(client *redis.Client) repository.SessionRepository {
 	return &RedisSessionRepository{client: client}
 }
 
+// userSessionsKey kullanıcının oturum anahtarlarını tutan set'in anahtarı;
+// listeleme ve toplu silme KEYS taramasına ihtiyaç duymaz
+func userSessionsKey(userID string) string {
+	return "user_sessions:" + userID
+}
+
+// clientSessionsKey OAuth istemcisine verilmiş oturumların anahtarlarını
+// tutan set; istemci devre dışı bırakıldığında oturumları bulmak için kullanılır
+func clientSessionsKey(clientID string) string {
+	return "client_sessions:" + clientID
+}
+
+// sessionPrefix kullanıcının oturum anahtarlarının ortak ön eki
+func sessionPrefix(userID string) string {
+	return "session:" + userID + ":"
+}
+
+// userIDFromSessionKey "session:<userID>:<sessionID>" biçimindeki anahtardan kullanıcı kimliğini çıkarır
+func userIDFromSessionKey(sessionID string) string {
+	parts := strings.SplitN(sessionID, ":", 3)
+	if len(parts) != 3 || parts[0] != "session" {
+		return ""
+	}
+	return parts[1]
+}
+
 func (r *RedisSessionRepository) Create(ctx context.Context, sessionID string, session *entity.Session, ttl time.Duration) error {
 	data, err := json.Marshal(session)
 	if err != nil {
 		return err
 	}
-	return r.client.Set(ctx, sessionID, data, ttl).Err()
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		writeSession(ctx, pipe, sessionID, session, data, ttl)
+		return nil
+	})
+	return err
+}
+
+// CompareAndSwap oturum anahtarını WATCH ile izler; okuma ile yazma arasında
+// anahtarı değiştiren başka bir istek olursa işlem uygulanmaz ve false döner
+func (r *RedisSessionRepository) CompareAndSwap(ctx context.Context, sessionID, field, expected string, session *entity.Session, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return false, err
+	}
+
+	swapped := false
+	err = r.client.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.Get(ctx, sessionID).Bytes()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var current entity.Session
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return err
+		}
+		if value, _ := current.Data[field].(string); value != expected {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			writeSession(ctx, pipe, sessionID, session, data, ttl)
+			return nil
+		})
+		swapped = err == nil
+		return err
+	}, sessionID)
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return swapped, err
+}
+
+func writeSession(ctx context.Context, pipe redis.Pipeliner, sessionID string, session *entity.Session, data []byte, ttl time.Duration) {
+	pipe.Set(ctx, sessionID, data, ttl)
+	if session.UserID != "" {
+		// Index, kullanıcının en son oturumu kadar yaşar
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), sessionID)
+		pipe.Expire(ctx, userSessionsKey(session.UserID), ttl)
+	}
+	if clientID, _ := session.Data[entity.SessionClientID].(string); clientID != "" {
+		pipe.SAdd(ctx, clientSessionsKey(clientID), sessionID)
+		pipe.Expire(ctx, clientSessionsKey(clientID), ttl)
+	}
 }
 
 func (r *RedisSessionRepository) Get(ctx context.Context, sessionID string) (*entity.Session, error) {
@@ -44,17 +129,81 @@ func (r *RedisSessionRepository) Get(ctx context.Context, sessionID string) (*en
 }
 
 func (r *RedisSessionRepository) Delete(ctx context.Context, sessionID string) error {
-	return r.client.Del(ctx, sessionID).Err()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionID)
+		if userID := userIDFromSessionKey(sessionID); userID != "" {
+			pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+		}
+		return nil
+	})
+	return err
 }
 
 func (r *RedisSessionRepository) DeleteAllUserSessions(ctx context.Context, userID string) error {
-	pattern := "session:" + userID + ":*"
-	keys, err := r.client.Keys(ctx, pattern).Result()
+	keys, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
 	if err != nil {
 		return err
 	}
-	if len(keys) > 0 {
-		return r.client.Del(ctx, keys...).Err()
+
+	keys = append(keys, userSessionsKey(userID))
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// ListUserSessions kullanıcının aktif oturumlarını kimlikleriyle birlikte döner;
+// süresi dolmuş oturumlar index'ten temizlenir
+func (r *RedisSessionRepository) ListUserSessions(ctx context.Context, userID string) ([]*entity.Session, error) {
+	return r.listIndexedSessions(ctx, userSessionsKey(userID), func(*entity.Session) string {
+		return sessionPrefix(userID)
+	})
+}
+
+// ListClientSessions istemcinin index'teki oturumlarını kimlikleriyle birlikte
+// döner; süresi dolmuş veya silinmiş oturumlar index'ten temizlenir
+func (r *RedisSessionRepository) ListClientSessions(ctx context.Context, clientID string) ([]*entity.Session, error) {
+	return r.listIndexedSessions(ctx, clientSessionsKey(clientID), func(session *entity.Session) string {
+		return sessionPrefix(session.UserID)
+	})
+}
+
+// listIndexedSessions index set'indeki oturum anahtarlarını tek MGET ile
+// okur; oturum kimliği anahtardan idPrefix atılarak elde edilir. Artık var
+// olmayan anahtarlar index'ten silinir.
+func (r *RedisSessionRepository) listIndexedSessions(ctx context.Context, indexKey string, idPrefix func(*entity.Session) string) ([]*entity.Session, error) {
+	keys, err := r.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []*entity.Session{}, nil
 	}
-	return nil
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*entity.Session, 0, len(keys))
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, keys[i])
+			continue
+		}
+
+		var session entity.Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, err
+		}
+		session.ID = strings.TrimPrefix(keys[i], idPrefix(&session))
+		sessions = append(sessions, &session)
+	}
+
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, indexKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
 }
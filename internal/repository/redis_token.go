@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"auth-service/internal/domain/repository"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisTokenRepository struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewTokenRepository ttl, en uzun ömürlü access token'ın süresi olmalıdır;
+// kayıtlar bu süreden sonra zaten anlamsızdır
+func NewTokenRepository(client *redis.Client, ttl time.Duration) repository.TokenRepository {
+	return &RedisTokenRepository{client: client, ttl: ttl}
+}
+
+func (r *RedisTokenRepository) Revoke(ctx context.Context, tokenID string) error {
+	return r.client.Set(ctx, "revoked_token:"+tokenID, 1, r.ttl).Err()
+}
+
+func (r *RedisTokenRepository) IsRevoked(ctx context.Context, tokenIDs ...string) (bool, error) {
+	keys := make([]string, 0, len(tokenIDs))
+	for _, id := range tokenIDs {
+		if id != "" {
+			keys = append(keys, "revoked_token:"+id)
+		}
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	count, err := r.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *RedisTokenRepository) RevokeAllForUser(ctx context.Context, userID string, at time.Time) error {
+	return r.client.Set(ctx, "tokens_revoked_at:"+userID, at.UnixMilli(), r.ttl).Err()
+}
+
+func (r *RedisTokenRepository) GetUserRevokedAt(ctx context.Context, userID string) (*time.Time, error) {
+	millis, err := r.client.Get(ctx, "tokens_revoked_at:"+userID).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	revokedAt := time.UnixMilli(millis)
+	return &revokedAt, nil
+}
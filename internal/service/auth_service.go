@@ -3,7 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"auth-service/internal/domain/entity"
 	"auth-service/internal/domain/oauth"
@@ -15,19 +20,88 @@ import (
 )
 
 var (
-	ErrInvalidCredentials = errors.New("geçersiz kimlik bilgileri")
-	ErrUserExists         = errors.New("kullanıcı zaten mevcut")
+	ErrInvalidCredentials  = errors.New("geçersiz kimlik bilgileri")
+	ErrUserExists          = errors.New("kullanıcı zaten mevcut")
+	ErrInvalidSession      = errors.New("oturum geçersiz veya süresi dolmuş")
+	ErrRefreshTokenReused  = errors.New("refresh token daha önce kullanılmış, oturum sonlandırıldı")
+	ErrUserNotFound        = errors.New("kullanıcı bulunamadı")
+	ErrInvalidRole         = errors.New("geçersiz rol")
+	ErrInvalid2FACode      = errors.New("geçersiz 2FA kodu")
+	ErrInvalidRecoveryCode = errors.New("geçersiz veya kullanılmış kurtarma kodu")
+	Err2FANotEnabled       = errors.New("2FA etkin değil")
+	ErrNo2FAEnrollment     = errors.New("doğrulanacak 2FA kaydı yok, önce 2FA'yı etkinleştirin")
+	ErrEmailNotVerified    = errors.New("email adresi doğrulanmamış")
+	ErrInvalidVerifyToken  = errors.New("geçersiz veya süresi dolmuş doğrulama token'ı")
+	ErrInvalidResetToken   = errors.New("geçersiz veya süresi dolmuş token")
+	ErrSessionNotFound     = errors.New("oturum bulunamadı")
+	ErrPasswordBreached    = errors.New("bu şifre bilinen veri sızıntılarında yer alıyor, farklı bir şifre seçin")
+	ErrPasswordReused      = errors.New("bu şifre yakın zamanda kullanıldı, daha önce kullanmadığınız bir şifre seçin")
+	ErrTooManyUsers        = fmt.Errorf("tek istekte en fazla %d kullanıcı işlenebilir", maxBulkUsers)
 )
 
+// Email doğrulama modları
+const (
+	EmailVerificationOptional = "optional" // Doğrulama girişi etkilemez
+	EmailVerificationRequired = "required" // Doğrulanmamış kullanıcı giriş yapamaz
+	EmailVerificationLimited  = "limited"  // Doğrulanmamış kullanıcı kısıtlı token alır
+)
+
+// recoveryCodeCount 2FA etkinleştirildiğinde üretilen kurtarma kodu sayısı
+const recoveryCodeCount = 10
+
+// userTokenSize email ile gönderilen token'ların rastgele bayt sayısı (256 bit)
+const userTokenSize = 32
+
+// maxBulkUsers toplu yönetici işlemlerinde tek istekte işlenebilecek kullanıcı sayısı
+const maxBulkUsers = 1000
+
+// sessionRefreshTokenKey oturumdaki geçerli refresh token parmak izinin anahtarı
+const sessionRefreshTokenKey = "refresh_token_hash"
+
+// errRefreshTokenSuperseded yenilenen refresh token'ın oturumdaki güncel
+// token olmadığını (daha önce veya eşzamanlı olarak kullanıldığını) bildirir
+var errRefreshTokenSuperseded = errors.New("refresh token oturumdaki güncel token değil")
+
+// AuthConfig kimlik doğrulama akışlarının dağıtıma göre ayarlanabilen kuralları
+type AuthConfig struct {
+	EmailVerificationMode string
+	EmailVerificationTTL  time.Duration
+	PasswordResetTTL      time.Duration
+	// BreachCheckOnLogin girişte şifreyi sızıntı veri setinde arar, bulunursa
+	// kullanıcıyı şifre değiştirmeye yönlendirir
+	BreachCheckOnLogin bool
+	PasswordPolicy     validator.PasswordPolicy
+	// PasswordHistorySize yeni şifrenin eşleşmemesi gereken önceki şifre sayısı;
+	// mevcut şifre her durumda reddedilir (0: yalnızca mevcut şifre)
+	PasswordHistorySize int
+	// PasswordMaxAge bu süreden eski şifreyle giriş yapan kullanıcı yalnızca
+	// şifresini değiştirebilir (0: süresiz); PasswordMaxAgeByRole rol bazında ezer
+	PasswordMaxAge       time.Duration
+	PasswordMaxAgeByRole map[entity.Role]time.Duration
+	// OAuthStateTTL sosyal girişte sağlayıcıdan dönüş için tanınan süre
+	OAuthStateTTL time.Duration
+	// OAuthRedirectAllowlist sosyal girişten sonra yönlendirilebilecek istemci adresleri (birebir eşleşme)
+	OAuthRedirectAllowlist []string
+}
+
 type AuthService struct {
 	userRepo        repository.UserRepository
 	jwtManager      *security.JWTManager
+	passwordHasher  *security.PasswordHasher
 	emailService    *EmailService
 	totpService     *TOTPService
 	sessionRepo     repository.SessionRepository
 	auditRepo       repository.AuditRepository
+	recoveryRepo    repository.RecoveryCodeRepository
+	historyRepo     repository.PasswordHistoryRepository
+	tokenRepo       repository.UserTokenRepository
+	oauthStateRepo  repository.OAuthStateRepository
+	oauthLinkRepo   repository.OAuthLinkRepository
+	identityRepo    repository.UserIdentityRepository
+	breachCorpus    *security.BreachCorpus
 	securityService *SecurityService
-	oauthProvider   oauth.Provider
+	oauthProviders  *oauth.Registry
+	config          AuthConfig
 }
 
 type RegisterInput struct {
@@ -40,25 +114,81 @@ type LoginInput struct {
 	Password string
 }
 
+type Login2FAInput struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// SessionInfo kullanıcıya gösterilen aktif oturum bilgisi
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+	// ClientID oturum bir OAuth istemcisine verilmişse istemcinin kimliği
+	ClientID string `json:"client_id,omitempty"`
+}
+
+// ForcePasswordChangeResult toplu şifre değişikliği zorunluluğunun sonucu
+type ForcePasswordChangeResult struct {
+	Flagged  []string `json:"flagged"`
+	NotFound []string `json:"not_found,omitempty"`
+}
+
+// LoginResult ya token pair'i ya da 2FA kullanıcıları için ikinci adımda
+// kullanılacak kısa ömürlü MFA challenge token'ını taşır
+type LoginResult struct {
+	*entity.TokenPair
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// LinkRequired sosyal girişin emaili mevcut bir hesaba ait olduğunda döner;
+	// LinkToken hesap şifresiyle birlikte gönderilerek hesaplar bağlanır
+	LinkRequired bool   `json:"link_required,omitempty"`
+	LinkToken    string `json:"link_token,omitempty"`
+}
+
 func NewAuthService(
 	userRepo repository.UserRepository,
 	jwtManager *security.JWTManager,
+	passwordHasher *security.PasswordHasher,
 	emailService *EmailService,
 	totpService *TOTPService,
 	sessionRepo repository.SessionRepository,
 	auditRepo repository.AuditRepository,
+	recoveryRepo repository.RecoveryCodeRepository,
+	historyRepo repository.PasswordHistoryRepository,
+	tokenRepo repository.UserTokenRepository,
+	oauthStateRepo repository.OAuthStateRepository,
+	oauthLinkRepo repository.OAuthLinkRepository,
+	identityRepo repository.UserIdentityRepository,
+	breachCorpus *security.BreachCorpus,
 	securityService *SecurityService,
-	oauthProvider oauth.Provider,
+	oauthProviders *oauth.Registry,
+	config AuthConfig,
 ) *AuthService {
 	return &AuthService{
 		userRepo:        userRepo,
 		jwtManager:      jwtManager,
+		passwordHasher:  passwordHasher,
 		emailService:    emailService,
 		totpService:     totpService,
 		sessionRepo:     sessionRepo,
 		auditRepo:       auditRepo,
+		recoveryRepo:    recoveryRepo,
+		historyRepo:     historyRepo,
+		tokenRepo:       tokenRepo,
+		oauthStateRepo:  oauthStateRepo,
+		oauthLinkRepo:   oauthLinkRepo,
+		identityRepo:    identityRepo,
+		breachCorpus:    breachCorpus,
 		securityService: securityService,
-		oauthProvider:   oauthProvider,
+		oauthProviders:  oauthProviders,
+		config:          config,
 	}
 }
 
@@ -72,31 +202,131 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*entit
 		return nil, ErrUserExists
 	}
 
+	if err := s.validateNewPassword(input.Password, input.Email); err != nil {
+		return nil, err
+	}
+
 	// Şifreyi hashle
-	hashedPassword, err := security.HashPassword(input.Password)
+	hashedPassword, err := s.passwordHasher.Hash(input.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	// Yeni kullanıcı oluştur
+	now := time.Now()
 	user := &entity.User{
-		ID:        uuid.New().String(),
-		Email:     input.Email,
-		Password:  hashedPassword,
-		Role:      entity.RoleUser,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                uuid.New().String(),
+		Email:             input.Email,
+		Password:          hashedPassword,
+		Role:              entity.RoleUser,
+		PasswordChangedAt: &now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	// Kullanıcıyı kaydet
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	if err := s.recordPasswordHistory(ctx, user.ID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	// Email doğrulama token'ı üret
+	verificationToken, err := s.issueUserToken(ctx, user.ID, entity.TokenPurposeEmailVerify, s.config.EmailVerificationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email gönderilemese de kayıt tamamlanır; kullanıcı yeniden gönderim isteyebilir
+	if err := s.emailService.SendVerificationEmail(user.Email, verificationToken); err != nil {
+		log.Printf("doğrulama emaili gönderilemedi (%s): %v", user.ID, err)
+	}
 
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, input LoginInput) (*entity.TokenPair, error) {
+// VerifyEmail doğrulama token'ını kontrol eder ve kullanıcının emailini doğrulanmış işaretler
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	userToken, err := s.tokenRepo.GetValid(ctx, security.HashToken(token), entity.TokenPurposeEmailVerify)
+	if err != nil {
+		return err
+	}
+	if userToken == nil {
+		return ErrInvalidVerifyToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidVerifyToken
+	}
+
+	consumed, err := s.tokenRepo.Consume(ctx, userToken.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidVerifyToken
+	}
+	if err := s.dropUnprovenIdentities(ctx, user); err != nil {
+		return err
+	}
+
+	user.IsVerified = true
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.UpdateFields(ctx, user, "IsVerified", "UpdatedAt"); err != nil {
+		return err
+	}
+
+	return s.recordAudit(ctx, user.ID, entity.ActionEmailVerify, true, "Email adresi doğrulandı")
+}
+
+// ResendVerificationEmail doğrulanmamış kullanıcıya yeni bir doğrulama token'ı gönderir.
+// Hesap varlığını sızdırmamak için bilinmeyen veya doğrulanmış adreslerde hata dönmez.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.IsVerified {
+		return nil
+	}
+
+	token, err := s.issueUserToken(ctx, user.ID, entity.TokenPurposeEmailVerify, s.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.emailService.SendVerificationEmail(user.Email, token)
+}
+
+// issueUserToken kullanıcı için amaca bağlı tek kullanımlık bir token üretir;
+// aynı amaçlı önceki token'lar geçersiz olur. Yalnızca özeti saklanır.
+func (s *AuthService) issueUserToken(ctx context.Context, userID string, purpose entity.TokenPurpose, ttl time.Duration) (string, error) {
+	token, err := security.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.tokenRepo.Replace(ctx, &entity.UserToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: security.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AuthService) Login(ctx context.Context, input LoginInput) (*LoginResult, error) {
 	// Kullanıcıyı bul
 	user, err := s.userRepo.GetByEmail(ctx, input.Email)
 	if err != nil {
@@ -106,13 +336,307 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput) (*entity.Toke
 		return nil, ErrInvalidCredentials
 	}
 
+	// Engellenmiş, kilitli veya bekleme süresindeki hesaplarda şifre denenmez
+	if err := s.securityService.CheckLoginAllowed(user); err != nil {
+		return nil, s.loginFailed(ctx, user, err.Error(), false, err)
+	}
+
 	// Şifreyi kontrol et
-	if !security.CheckPassword(input.Password, user.Password) {
+	if !s.passwordHasher.Verify(input.Password, user.Password) {
+		return nil, s.loginFailed(ctx, user, "Geçersiz şifre", true, ErrInvalidCredentials)
+	}
+
+	// Eski algoritma veya parametrelerle oluşturulmuş hash'i güncelle
+	s.upgradePasswordHash(ctx, user, input.Password)
+
+	if s.config.BreachCheckOnLogin && !user.MustChangePassword {
+		if err := s.flagBreachedPassword(ctx, user, input.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	if !user.IsVerified && s.config.EmailVerificationMode == EmailVerificationRequired {
+		return nil, s.loginFailed(ctx, user, "Email adresi doğrulanmamış", false, ErrEmailNotVerified)
+	}
+
+	// 2FA açıksa token yerine ikinci adım için challenge dön
+	if user.Is2FAEnabled {
+		mfaToken, err := s.jwtManager.GenerateMFAToken(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	// Yeni oturum aç ve token pair oluştur
+	tokens, err := s.completeLogin(ctx, user, "şifre")
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{TokenPair: tokens}, nil
+}
+
+// PasswordPolicy istemcilerin kuralları gösterebilmesi için geçerli şifre politikasını döner
+func (s *AuthService) PasswordPolicy() validator.PasswordPolicy {
+	return s.config.PasswordPolicy
+}
+
+// validateNewPassword yeni belirlenen şifreyi kurallara ve sızıntı veri setine
+// göre denetler; email'in yerel kısmı şifrede geçemez
+func (s *AuthService) validateNewPassword(password, email string) error {
+	localPart, _, _ := strings.Cut(email, "@")
+	if err := s.config.PasswordPolicy.Validate(password, localPart); err != nil {
+		return err
+	}
+
+	breached, err := s.breachCorpus.Contains(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		return ErrPasswordBreached
+	}
+	return nil
+}
+
+// checkPasswordReuse yeni şifrenin mevcut şifre veya son PasswordHistorySize
+// şifreden biriyle aynı olup olmadığını kontrol eder; eşleşme güvenlik loguna yazılır
+func (s *AuthService) checkPasswordReuse(ctx context.Context, user *entity.User, password string) error {
+	reused := s.passwordHasher.Verify(password, user.Password)
+	if !reused && s.config.PasswordHistorySize > 0 {
+		history, err := s.historyRepo.ListRecent(ctx, user.ID, s.config.PasswordHistorySize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range history {
+			if s.passwordHasher.Verify(password, entry.PasswordHash) {
+				reused = true
+				break
+			}
+		}
+	}
+	if !reused {
+		return nil
+	}
+
+	if err := s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      user.ID,
+		Action:      entity.ActionPasswordReuse,
+		Description: "Daha önce kullanılmış şifre yeniden belirlenmek istendi",
+		CreatedBy:   user.ID,
+	}); err != nil {
+		return err
+	}
+	return ErrPasswordReused
+}
+
+// recordPasswordHistory yeni şifre hash'ini geçmişe ekler ve en yeni
+// PasswordHistorySize kayıt dışındakileri temizler
+func (s *AuthService) recordPasswordHistory(ctx context.Context, userID, passwordHash string) error {
+	if s.config.PasswordHistorySize <= 0 {
+		return nil
+	}
+	return s.historyRepo.Add(ctx, &entity.PasswordHistory{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+	}, s.config.PasswordHistorySize)
+}
+
+// flagBreachedPassword girişte kullanılan şifre sızıntı veri setindeyse
+// kullanıcıyı şifre değiştirmeye zorlar ve güvenlik loguna yazar
+func (s *AuthService) flagBreachedPassword(ctx context.Context, user *entity.User, password string) error {
+	breached, err := s.breachCorpus.Contains(password)
+	if err != nil {
+		// Veri seti okunamazsa giriş engellenmez
+		log.Printf("sızıntı kontrolü yapılamadı (%s): %v", user.ID, err)
+		return nil
+	}
+	if !breached {
+		return nil
+	}
+
+	user.MustChangePassword = true
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.UpdateFields(ctx, user, "MustChangePassword", "UpdatedAt"); err != nil {
+		return err
+	}
+
+	return s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      user.ID,
+		Action:      entity.ActionPasswordBreached,
+		Description: "Giriş şifresi sızıntı veri setinde bulundu, şifre değişikliği zorunlu kılındı",
+		CreatedBy:   "system",
+	})
+}
+
+// upgradePasswordHash doğrulanmış şifreyi güncel hash ayarlarıyla yeniden
+// hashler; hata girişi engellemez, bir sonraki girişte tekrar denenir
+func (s *AuthService) upgradePasswordHash(ctx context.Context, user *entity.User, password string) {
+	if !s.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		log.Printf("şifre hash'i güncellenemedi (%s): %v", user.ID, err)
+		return
+	}
+
+	user.Password = hashedPassword
+	if err := s.userRepo.UpdateFields(ctx, user, "Password"); err != nil {
+		log.Printf("şifre hash'i güncellenemedi (%s): %v", user.ID, err)
+	}
+}
+
+// Login2FA şifre adımını geçmiş kullanıcının TOTP kodunu doğrular ve token pair üretir
+func (s *AuthService) Login2FA(ctx context.Context, input Login2FAInput) (*entity.TokenPair, error) {
+	claims, err := s.jwtManager.ValidateToken(input.MFAToken, entity.MFAToken)
+	if err != nil {
 		return nil, ErrInvalidCredentials
 	}
 
-	// Token pair oluştur
-	return s.jwtManager.GenerateTokenPair(user)
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.Is2FAEnabled {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Challenge alındıktan sonra engellenen veya kilitlenen hesaplar devam edemez
+	if err := s.securityService.CheckLoginAllowed(user); err != nil {
+		return nil, s.loginFailed(ctx, user, err.Error(), false, err)
+	}
+
+	// Kimlik doğrulayıcısını kaybeden kullanıcı TOTP yerine kurtarma kodu kullanabilir
+	if input.RecoveryCode != "" {
+		if err := s.useRecoveryCode(ctx, user, input.RecoveryCode); err != nil {
+			return nil, err
+		}
+		return s.completeLogin(ctx, user, "şifre + kurtarma kodu")
+	}
+
+	if !s.totpService.ValidateCode(user.TOTPSecret, input.Code) {
+		return nil, s.loginFailed(ctx, user, "Geçersiz 2FA kodu", true, ErrInvalid2FACode)
+	}
+
+	return s.completeLogin(ctx, user, "şifre + TOTP")
+}
+
+// completeLogin tüm doğrulama adımları geçildikten sonra sayaçları sıfırlar,
+// oturum açar ve girişi denetim loguna yazar
+func (s *AuthService) completeLogin(ctx context.Context, user *entity.User, method string) (*entity.TokenPair, error) {
+	if err := s.securityService.RegisterSuccessfulLogin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordAudit(ctx, user.ID, entity.ActionLogin, true, "Giriş başarılı: "+method); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// loginFailed başarısız giriş adımını denetim loguna yazar, countAttempt ise
+// kilit sayacına da işler ve istemciye dönülecek hatayı verir
+func (s *AuthService) loginFailed(ctx context.Context, user *entity.User, reason string, countAttempt bool, result error) error {
+	if countAttempt {
+		if err := s.securityService.RegisterFailedLogin(ctx, user, reason); err != nil {
+			return err
+		}
+	}
+	if err := s.recordAudit(ctx, user.ID, entity.ActionLogin, false, reason); err != nil {
+		return err
+	}
+	return result
+}
+
+// useRecoveryCode kurtarma kodunu tüketir, denetim kaydı yazar ve kullanıcıyı
+// email ile uyarır
+func (s *AuthService) useRecoveryCode(ctx context.Context, user *entity.User, code string) error {
+	codeHash := security.HashToken(s.totpService.NormalizeRecoveryCode(code))
+	ok, err := s.recoveryRepo.Consume(ctx, user.ID, codeHash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.loginFailed(ctx, user, "Geçersiz kurtarma kodu", true, ErrInvalidRecoveryCode)
+	}
+
+	remaining, err := s.recoveryRepo.CountUnused(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(ctx, user.ID, entity.ActionRecoveryCode, true,
+		fmt.Sprintf("Kurtarma kodu ile giriş yapıldı, kalan kod: %d", remaining)); err != nil {
+		return err
+	}
+
+	// Uyarı emaili gönderilemese de giriş engellenmemeli
+	if err := s.emailService.SendRecoveryCodeUsedEmail(user.Email, remaining); err != nil {
+		log.Printf("kurtarma kodu uyarı emaili gönderilemedi (%s): %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes geçerli bir TOTP kodu karşılığında eski kurtarma
+// kodlarını geçersiz kılar ve yenilerini üretir
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.Is2FAEnabled {
+		return nil, Err2FANotEnabled
+	}
+
+	// Çalınmış bir access token'la kod tahmini girişteki kilit kurallarına tabidir
+	if err := s.securityService.CheckLoginAllowed(user); err != nil {
+		return nil, s.loginFailed(ctx, user, "Kurtarma kodu yenileme: "+err.Error(), false, err)
+	}
+	if !s.totpService.ValidateCode(user.TOTPSecret, code) {
+		return nil, s.loginFailed(ctx, user, "Kurtarma kodu yenileme: geçersiz 2FA kodu", true, ErrInvalid2FACode)
+	}
+
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+// generateRecoveryCodes yeni kodları üretir, yalnızca özetlerini saklar ve
+// düz metin kodları tek seferlik gösterilmek üzere döner
+func (s *AuthService) generateRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes, err := s.totpService.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := make([]entity.RecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = entity.RecoveryCode{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			CodeHash:  security.HashToken(s.totpService.NormalizeRecoveryCode(code)),
+			CreatedAt: now,
+		}
+	}
+
+	if err := s.recoveryRepo.ReplaceForUser(ctx, userID, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
 }
 
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*entity.TokenPair, error) {
@@ -122,6 +646,20 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*e
 		return nil, err
 	}
 
+	// Token'ın bağlı olduğu oturumu bul; OAuth istemcilerinin token'ları
+	// yalnızca /oauth/token ucunda yenilenir
+	if claims.SessionID == "" || claims.ClientID != "" {
+		return nil, ErrInvalidSession
+	}
+	key := sessionKey(claims.UserID, claims.SessionID)
+	session, err := s.sessionRepo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInvalidSession
+	}
+
 	// Kullanıcıyı bul
 	user, err := s.userRepo.GetByID(ctx, claims.UserID)
 	if err != nil {
@@ -130,9 +668,202 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*e
 	if user == nil {
 		return nil, ErrInvalidCredentials
 	}
+	if !user.IsActive {
+		return nil, ErrUserBlocked
+	}
 
-	// Yeni token pair oluştur
-	return s.jwtManager.GenerateTokenPair(user)
+	// Token'ı döndür: eski refresh token bu noktadan sonra geçersiz. Oturumdaki
+	// güncel token değilse daha önce kullanılmış (çalınmış olabilir); kontrol
+	// ve yazma atomik olduğundan aynı token'la gelen eşzamanlı isteklerden
+	// yalnızca biri yeni token alır
+	tokens, err := s.rotateSession(ctx, user, claims.SessionID, session, security.HashToken(refreshToken))
+	if errors.Is(err, errRefreshTokenSuperseded) {
+		if err := s.securityService.RevokeReusedRefreshToken(ctx, claims); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	return tokens, err
+}
+
+// startSession kullanıcı için yeni bir oturum (token ailesi) başlatır
+func (s *AuthService) startSession(ctx context.Context, user *entity.User) (*entity.TokenPair, error) {
+	meta := RequestMetaFromContext(ctx)
+	now := time.Now().UTC()
+	session := &entity.Session{
+		UserID: user.ID,
+		Data: map[string]interface{}{
+			entity.SessionDevice:    describeDevice(meta.UserAgent),
+			entity.SessionUserAgent: truncate(meta.UserAgent, 255),
+			entity.SessionIP:        meta.IP,
+			entity.SessionCreatedAt: now,
+		},
+	}
+	return s.rotateSession(ctx, user, uuid.New().String(), session, "")
+}
+
+// rotateSession oturum için yeni token pair üretir ve oturumda yalnızca
+// yeni refresh token'ın parmak izini tutar. previousHash verilirse oturum
+// yalnızca hâlâ o token'a aitse güncellenir, aksi halde
+// errRefreshTokenSuperseded döner.
+func (s *AuthService) rotateSession(ctx context.Context, user *entity.User, sessionID string, session *entity.Session, previousHash string) (*entity.TokenPair, error) {
+	tokens, err := s.jwtManager.GenerateTokenPair(user, sessionID, s.tokenScope(user))
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.jwtManager.RefreshTokenTTL()
+	if session.Data == nil {
+		session.Data = map[string]interface{}{}
+	}
+	session.Data[sessionRefreshTokenKey] = security.HashToken(tokens.RefreshToken)
+	session.Data[entity.SessionLastUsedAt] = time.Now().UTC()
+	session.ExpiresAt = time.Now().Add(ttl)
+
+	if err := saveSession(ctx, s.sessionRepo, sessionKey(user.ID, sessionID), session, previousHash, ttl); err != nil {
+		return nil, err
+	}
+
+	tokens.PasswordChangeRequired = s.passwordChangeRequired(user)
+	return tokens, nil
+}
+
+// ListSessions kullanıcının aktif oturumlarını en son kullanılandan başlayarak döner;
+// currentSessionID ile eşleşen oturum işaretlenir
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]SessionInfo, error) {
+	sessions, err := s.sessionRepo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, SessionInfo{
+			ID:         session.ID,
+			Device:     sessionString(session, entity.SessionDevice),
+			UserAgent:  sessionString(session, entity.SessionUserAgent),
+			IP:         sessionString(session, entity.SessionIP),
+			CreatedAt:  sessionTime(session, entity.SessionCreatedAt),
+			LastUsedAt: sessionTime(session, entity.SessionLastUsedAt),
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+			ClientID:   sessionString(session, entity.SessionClientID),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastUsedAt.After(infos[j].LastUsedAt)
+	})
+	return infos, nil
+}
+
+// RevokeSession kullanıcının tek bir oturumunu sonlandırır; revokedBy kullanıcının
+// kendisi değilse işlem yönetici müdahalesi olarak güvenlik loguna da yazılır
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID, revokedBy string) error {
+	session, err := s.sessionRepo.Get(ctx, sessionKey(userID, sessionID))
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.securityService.RevokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("Oturum %s kapatıldı (%s)", sessionID, sessionString(session, entity.SessionDevice))
+	if revokedBy != userID {
+		if err := s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+			UserID:      userID,
+			Action:      entity.ActionForceLogout,
+			Description: details,
+			CreatedBy:   revokedBy,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return s.recordAudit(ctx, userID, entity.ActionLogout, true, details)
+}
+
+// sessionString oturum verisindeki metin alanını döner
+func sessionString(session *entity.Session, key string) string {
+	value, _ := session.Data[key].(string)
+	return value
+}
+
+// sessionTime oturum verisindeki zaman alanını döner; JSON'dan okunan
+// değerler RFC 3339 metni olarak gelir
+func sessionTime(session *entity.Session, key string) time.Time {
+	switch value := session.Data[key].(type) {
+	case time.Time:
+		return value
+	case string:
+		parsed, _ := time.Parse(time.RFC3339Nano, value)
+		return parsed
+	}
+	return time.Time{}
+}
+
+// tokenScope kullanıcının mevcut durumuna göre token kapsamını belirler;
+// boş kapsam tam yetki anlamına gelir
+func (s *AuthService) tokenScope(user *entity.User) string {
+	if s.passwordChangeRequired(user) {
+		return entity.ScopePasswordChange
+	}
+	if !user.IsVerified && s.config.EmailVerificationMode == EmailVerificationLimited {
+		return entity.ScopeUnverified
+	}
+	return ""
+}
+
+// passwordChangeRequired kullanıcı işaretlenmişse veya şifresi rolü için
+// tanımlı azami yaşı geçmişse true döner
+func (s *AuthService) passwordChangeRequired(user *entity.User) bool {
+	// Şifresi olmayan (yalnızca sosyal girişli) hesaplarda değiştirilecek şifre yoktur
+	if user.PasswordUnusable {
+		return false
+	}
+	if user.MustChangePassword {
+		return true
+	}
+
+	maxAge, ok := s.config.PasswordMaxAgeByRole[user.Role]
+	if !ok {
+		maxAge = s.config.PasswordMaxAge
+	}
+	if maxAge <= 0 {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > maxAge
+}
+
+// sessionKey oturumun Redis anahtarını üretir
+func sessionKey(userID, sessionID string) string {
+	return "session:" + userID + ":" + sessionID
+}
+
+// saveSession yeni oturumu yazar; previousHash verilirse oturumu yalnızca
+// saklanan refresh token parmak izi hâlâ previousHash ise atomik olarak günceller
+func saveSession(ctx context.Context, sessionRepo repository.SessionRepository, key string, session *entity.Session, previousHash string, ttl time.Duration) error {
+	if previousHash == "" {
+		return sessionRepo.Create(ctx, key, session, ttl)
+	}
+
+	swapped, err := sessionRepo.CompareAndSwap(ctx, key, sessionRefreshTokenKey, previousHash, session, ttl)
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		return errRefreshTokenSuperseded
+	}
+	return nil
 }
 
 func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) error {
@@ -144,29 +875,173 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, oldPassword, n
 		return ErrInvalidCredentials
 	}
 
-	if !security.CheckPassword(oldPassword, user.Password) {
+	if !s.passwordHasher.Verify(oldPassword, user.Password) {
+		if err := s.recordAudit(ctx, userID, entity.ActionPasswordChange, false, "Mevcut şifre hatalı"); err != nil {
+			return err
+		}
 		return ErrInvalidCredentials
 	}
 
-	if err := validator.ValidatePassword(newPassword); err != nil {
+	if err := s.validateNewPassword(newPassword, user.Email); err != nil {
+		return err
+	}
+	if err := s.checkPasswordReuse(ctx, user, newPassword); err != nil {
 		return err
 	}
 
-	hashedPassword, err := security.HashPassword(newPassword)
+	hashedPassword, err := s.passwordHasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
 	user.Password = hashedPassword
-	user.UpdatedAt = time.Now()
+	user.MustChangePassword = false
+	user.PasswordChangedAt = &now
+	user.UpdatedAt = now
+
+	if err := s.userRepo.UpdateFields(ctx, user, "Password", "MustChangePassword", "PasswordChangedAt", "UpdatedAt"); err != nil {
+		return err
+	}
+	if err := s.recordPasswordHistory(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	// Bekleyen sıfırlama bağlantıları eski şifre için üretilmişti
+	if err := s.tokenRepo.DeleteForUser(ctx, user.ID, entity.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(ctx, userID, entity.ActionPasswordChange, true, "Şifre değiştirildi"); err != nil {
+		return err
+	}
+
+	return s.InvalidateTokens(ctx, userID)
+}
+
+// Logout isteği yapan token'ın oturumunu ve access token'ını iptal eder
+func (s *AuthService) Logout(ctx context.Context, claims *entity.TokenClaims) error {
+	if err := s.securityService.RevokeToken(ctx, claims.ID); err != nil {
+		return err
+	}
+	if claims.SessionID != "" {
+		if err := s.securityService.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	return s.recordAudit(ctx, claims.UserID, entity.ActionLogout, true, "Oturum kapatıldı")
+}
+
+// LogoutAll kullanıcının tüm oturumlarını kapatır; keepCurrent true ise
+// isteği yapan oturum açık bırakılır
+func (s *AuthService) LogoutAll(ctx context.Context, claims *entity.TokenClaims, keepCurrent bool) error {
+	if keepCurrent && claims.SessionID != "" {
+		revoked, err := s.securityService.RevokeOtherSessions(ctx, claims.UserID, claims.SessionID)
+		if err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, claims.UserID, entity.ActionLogout, true,
+			fmt.Sprintf("Mevcut oturum dışındaki %d oturum kapatıldı", revoked))
+	}
+
+	if err := s.InvalidateTokens(ctx, claims.UserID); err != nil {
+		return err
+	}
 
-	return s.userRepo.Update(ctx, user)
+	return s.recordAudit(ctx, claims.UserID, entity.ActionLogout, true, "Tüm oturumlar kapatıldı")
 }
 
+// InvalidateTokens kullanıcının tüm oturumlarını ve mevcut access token'larını iptal eder
 func (s *AuthService) InvalidateTokens(ctx context.Context, userID string) error {
-	// Redis'te kullanıcının tüm tokenlarını blacklist'e ekle
-	// Bu özellik için Redis repository'si güncellenmeli
-	return nil
+	return s.securityService.RevokeUserTokens(ctx, userID)
+}
+
+func (s *AuthService) ChangeUserRole(ctx context.Context, userID string, role entity.Role, changedBy string) error {
+	if !role.IsValid() {
+		return ErrInvalidRole
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	oldRole := user.Role
+	user.Role = role
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.UpdateFields(ctx, user, "Role", "UpdatedAt"); err != nil {
+		return err
+	}
+
+	if err := s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      userID,
+		Action:      entity.ActionRoleChange,
+		Description: fmt.Sprintf("Rol değiştirildi: %s -> %s", oldRole, role),
+		CreatedBy:   changedBy,
+	}); err != nil {
+		return err
+	}
+
+	// Eski rolü taşıyan token'lar artık kullanılamamalı
+	return s.InvalidateTokens(ctx, userID)
+}
+
+// RequirePasswordChange kullanıcıları bir sonraki girişte yeni şifre
+// belirlemeye zorlar; mevcut token'lar iptal edildiği için kısıtlama hemen geçerli olur
+func (s *AuthService) RequirePasswordChange(ctx context.Context, userIDs []string, reason, requestedBy string) (*ForcePasswordChangeResult, error) {
+	if len(userIDs) > maxBulkUsers {
+		return nil, ErrTooManyUsers
+	}
+
+	description := "Yönetici şifre değişikliğini zorunlu kıldı"
+	if reason != "" {
+		description += ": " + reason
+	}
+
+	result := &ForcePasswordChangeResult{Flagged: []string{}}
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			result.NotFound = append(result.NotFound, userID)
+			continue
+		}
+
+		user.MustChangePassword = true
+		user.UpdatedAt = time.Now()
+		if err := s.userRepo.UpdateFields(ctx, user, "MustChangePassword", "UpdatedAt"); err != nil {
+			return nil, err
+		}
+
+		if err := s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+			UserID:      userID,
+			Action:      entity.ActionForcePassword,
+			Description: description,
+			CreatedBy:   requestedBy,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := s.InvalidateTokens(ctx, userID); err != nil {
+			return nil, err
+		}
+		result.Flagged = append(result.Flagged, userID)
+	}
+
+	return result, nil
 }
 
 func (s *AuthService) InitiatePasswordReset(ctx context.Context, email string) error {
@@ -178,49 +1053,108 @@ func (s *AuthService) InitiatePasswordReset(ctx context.Context, email string) e
 		return nil // Güvenlik için kullanıcı bulunamadı hatası dönmeyelim
 	}
 
-	token := uuid.New().String()
-	expiresAt := time.Now().Add(24 * time.Hour)
-
-	user.PasswordResetToken = token
-	user.TokenExpiresAt = &expiresAt
+	token, err := s.issueUserToken(ctx, user.ID, entity.TokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.recordAudit(ctx, user.ID, entity.ActionPasswordReset, true, "Şifre sıfırlama talep edildi"); err != nil {
 		return err
 	}
 
 	return s.emailService.SendPasswordResetEmail(email, token)
 }
 
+// ResetPassword email ile gönderilen token'la yeni şifre belirler. Token
+// yalnızca şifre kurallara uyduğunda tüketilir, böylece kullanıcı aynı
+// bağlantıyla tekrar deneyebilir; başarılı sıfırlama tüm oturumları kapatır.
 func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
-	user, err := s.userRepo.GetByResetToken(ctx, token)
+	resetToken, err := s.tokenRepo.GetValid(ctx, security.HashToken(token), entity.TokenPurposePasswordReset)
 	if err != nil {
 		return err
 	}
-	if user == nil || user.TokenExpiresAt.Before(time.Now()) {
-		return errors.New("geçersiz veya süresi dolmuş token")
+	if resetToken == nil {
+		return ErrInvalidResetToken
 	}
 
-	if err := validator.ValidatePassword(newPassword); err != nil {
+	user, err := s.userRepo.GetByID(ctx, resetToken.UserID)
+	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
 
-	hashedPassword, err := security.HashPassword(newPassword)
+	if err := s.validateNewPassword(newPassword, user.Email); err != nil {
+		return err
+	}
+	if err := s.checkPasswordReuse(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
+	// Aynı token'la eşzamanlı iki sıfırlamadan yalnızca biri geçer
+	consumed, err := s.tokenRepo.Consume(ctx, resetToken.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidResetToken
+	}
+	if err := s.dropUnprovenIdentities(ctx, user); err != nil {
+		return err
+	}
+
+	now := time.Now()
 	user.Password = hashedPassword
-	user.PasswordResetToken = ""
-	user.TokenExpiresAt = nil
+	user.PasswordUnusable = false
+	user.MustChangePassword = false
+	user.PasswordChangedAt = &now
+	user.UpdatedAt = now
+
+	if err := s.userRepo.UpdateFields(ctx, user, "Password", "PasswordUnusable", "MustChangePassword", "PasswordChangedAt", "UpdatedAt"); err != nil {
+		return err
+	}
+	if err := s.recordPasswordHistory(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := s.recordAudit(ctx, user.ID, entity.ActionPasswordReset, true, "Şifre sıfırlama bağlantısı ile şifre yenilendi"); err != nil {
+		return err
+	}
 
-	return s.userRepo.Update(ctx, user)
+	if err := s.InvalidateTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// Şifre zaten değişti; bildirim gönderilemese de işlem başarılıdır
+	if err := s.emailService.SendPasswordChangedEmail(user.Email); err != nil {
+		log.Printf("şifre değişikliği emaili gönderilemedi (%s): %v", user.ID, err)
+	}
+	return nil
 }
 
-func (s *AuthService) Enable2FA(ctx context.Context, userID string) (string, string, error) {
+// Enable2FA yeni bir TOTP secret üretir. Secret Verify2FA ile doğrulanana kadar
+// beklemede tutulur; 2FA zaten açıksa yeniden kayıt için mevcut doğrulayıcıdan bir kod gerekir.
+func (s *AuthService) Enable2FA(ctx context.Context, userID, currentCode string) (string, string, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return "", "", err
 	}
+	if user == nil {
+		return "", "", ErrUserNotFound
+	}
+
+	if user.Is2FAEnabled && !s.totpService.ValidateCode(user.TOTPSecret, currentCode) {
+		if err := s.recordAudit(ctx, userID, entity.Action2FAEnable, false, "Yeniden kayıt için geçersiz mevcut kod"); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrInvalid2FACode
+	}
 
 	secret, err := s.totpService.GenerateSecret()
 	if err != nil {
@@ -232,24 +1166,172 @@ func (s *AuthService) Enable2FA(ctx context.Context, userID string) (string, str
 		return "", "", err
 	}
 
-	user.TOTPSecret = secret
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	user.PendingTOTPSecret = secret
+	if err := s.userRepo.UpdateFields(ctx, user, "PendingTOTPSecret"); err != nil {
 		return "", "", err
 	}
 
 	return secret, qrCode, nil
 }
 
-func (s *AuthService) Verify2FA(ctx context.Context, userID, code string) error {
+// Verify2FA kodu doğrulayıp 2FA'yı etkinleştirir ve kullanıcıya bir kez
+// gösterilecek kurtarma kodlarını döner
+func (s *AuthService) Verify2FA(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.PendingTOTPSecret == "" {
+		return nil, ErrNo2FAEnrollment
+	}
+
+	if !s.totpService.ValidateCode(user.PendingTOTPSecret, code) {
+		if err := s.recordAudit(ctx, userID, entity.Action2FAEnable, false, "Geçersiz doğrulama kodu"); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalid2FACode
+	}
+
+	reEnroll := user.Is2FAEnabled
+	user.TOTPSecret = user.PendingTOTPSecret
+	user.PendingTOTPSecret = ""
+	user.Is2FAEnabled = true
+	if err := s.userRepo.UpdateFields(ctx, user, "TOTPSecret", "PendingTOTPSecret", "Is2FAEnabled"); err != nil {
+		return nil, err
+	}
+
+	details := "2FA etkinleştirildi"
+	if reEnroll {
+		details = "2FA yeni kimlik doğrulayıcı ile yeniden kaydedildi"
+	}
+	if err := s.record2FAChange(ctx, userID, entity.Action2FAEnable, entity.Action2FAChange, details, userID); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+// Disable2FA kullanıcının kendi 2FA'sını şifre ve geçerli bir kod ile kapatmasını sağlar
+func (s *AuthService) Disable2FA(ctx context.Context, userID, password, code string) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !user.Is2FAEnabled {
+		return Err2FANotEnabled
+	}
 
+	if !s.passwordHasher.Verify(password, user.Password) {
+		if err := s.recordAudit(ctx, userID, entity.Action2FADisable, false, "Geçersiz şifre"); err != nil {
+			return err
+		}
+		return ErrInvalidCredentials
+	}
 	if !s.totpService.ValidateCode(user.TOTPSecret, code) {
-		return errors.New("geçersiz 2FA kodu")
+		if err := s.recordAudit(ctx, userID, entity.Action2FADisable, false, "Geçersiz 2FA kodu"); err != nil {
+			return err
+		}
+		return ErrInvalid2FACode
 	}
 
-	user.Is2FAEnabled = true
-	return s.userRepo.Update(ctx, user)
+	if err := s.clear2FA(ctx, user); err != nil {
+		return err
+	}
+
+	return s.record2FAChange(ctx, userID, entity.Action2FADisable, entity.Action2FAChange, "2FA kullanıcı tarafından devre dışı bırakıldı", userID)
+}
+
+// Reset2FA kimlik doğrulayıcısına erişimini kaybetmiş bir kullanıcının 2FA'sını
+// yetkili bir destek/güvenlik personeli adına sıfırlar
+func (s *AuthService) Reset2FA(ctx context.Context, userID, resetBy, reason string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !user.Is2FAEnabled && user.PendingTOTPSecret == "" {
+		return Err2FANotEnabled
+	}
+
+	if err := s.clear2FA(ctx, user); err != nil {
+		return err
+	}
+
+	details := "2FA yetkili tarafından sıfırlandı"
+	if reason != "" {
+		details += ": " + reason
+	}
+	return s.record2FAChange(ctx, userID, entity.Action2FADisable, entity.Action2FAReset, details, resetBy)
+}
+
+// clear2FA TOTP secret'larını ve kurtarma kodlarını siler
+func (s *AuthService) clear2FA(ctx context.Context, user *entity.User) error {
+	user.Is2FAEnabled = false
+	user.TOTPSecret = ""
+	user.PendingTOTPSecret = ""
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.UpdateFields(ctx, user, "Is2FAEnabled", "TOTPSecret", "PendingTOTPSecret", "UpdatedAt"); err != nil {
+		return err
+	}
+	return s.recoveryRepo.ReplaceForUser(ctx, user.ID, nil)
+}
+
+// record2FAChange 2FA durum değişikliğini hem denetim hem güvenlik loguna yazar
+func (s *AuthService) record2FAChange(ctx context.Context, userID string, auditAction entity.AuditAction, securityAction entity.SecurityAction, details, changedBy string) error {
+	if err := s.recordAudit(ctx, userID, auditAction, true, details); err != nil {
+		return err
+	}
+	return s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      userID,
+		Action:      securityAction,
+		Description: details,
+		CreatedBy:   changedBy,
+	})
+}
+
+// recordAudit kullanıcının denetim geçmişine, context'teki istek bilgileriyle birlikte kayıt ekler
+func (s *AuthService) recordAudit(ctx context.Context, userID string, action entity.AuditAction, status bool, details string) error {
+	meta := RequestMetaFromContext(ctx)
+	return s.auditRepo.Create(ctx, &entity.AuditLog{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Action:    action,
+		IP:        meta.IP,
+		UserAgent: truncate(meta.UserAgent, 255),
+		Status:    status,
+		Details:   details,
+		CreatedAt: time.Now(),
+	})
+}
+
+// GetAuditLogs kullanıcının kendi denetim geçmişini en yeniden eskiye döner
+func (s *AuthService) GetAuditLogs(ctx context.Context, userID string, limit, offset int) ([]entity.AuditLog, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.auditRepo.GetByUserID(ctx, userID, limit, offset)
+}
+
+// truncate metni veritabanı sütun uzunluğuna sığacak şekilde kırpar; çok
+// baytlı bir karakter ortadan bölünmez, geçersiz UTF-8 veritabanınca reddedilir
+func truncate(value string, n int) string {
+	if len(value) <= n {
+		return value
+	}
+	for n > 0 && !utf8.RuneStart(value[n]) {
+		n--
+	}
+	return value[:n]
 }
@@ -45,3 +45,32 @@ func (s *EmailService) SendPasswordResetEmail(to, token string) error {
 
 	return s.dialer.DialAndSend(m)
 }
+
+func (s *EmailService) SendPasswordChangedEmail(to string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Şifreniz Değiştirildi")
+	m.SetBody("text/html", `
+		<h1>Şifreniz Değiştirildi</h1>
+		<p>Hesabınızın şifresi sıfırlama bağlantısıyla değiştirildi ve tüm oturumlarınız kapatıldı.</p>
+		<p>Bu işlemi siz yapmadıysanız lütfen hemen destek ekibiyle iletişime geçin.</p>
+	`)
+
+	return s.dialer.DialAndSend(m)
+}
+
+func (s *EmailService) SendRecoveryCodeUsedEmail(to string, remaining int) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", "Kurtarma Kodu Kullanıldı")
+	m.SetBody("text/html", fmt.Sprintf(`
+		<h1>Hesabınıza Kurtarma Koduyla Giriş Yapıldı</h1>
+		<p>Hesabınızda iki adımlı doğrulama yerine bir kurtarma kodu kullanıldı.</p>
+		<p>Kalan kurtarma kodu sayısı: %d</p>
+		<p>Bu işlemi siz yapmadıysanız lütfen şifrenizi hemen değiştirin.</p>
+	`, remaining))
+
+	return s.dialer.DialAndSend(m)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+	"auth-service/pkg/security"
+)
+
+var ErrInvalidKeyPurpose = errors.New("geçersiz anahtar amacı, access, refresh veya mfa olmalı")
+
+// KeyService JWT imzalama anahtarlarını veritabanında saklar, tüm
+// instance'ların anahtarlığını bu kayıtlarla senkron tutar ve rotasyonu yönetir
+type KeyService struct {
+	keyRepo         repository.SigningKeyRepository
+	jwtManager      *security.JWTManager
+	securityService *SecurityService
+	// publishDelay yeni anahtarın yayınlanması ile imzalamaya başlaması
+	// arasındaki süre; diğer instance'lar ve JWKS önbellekleri bu sürede
+	// anahtarı öğrenir
+	publishDelay time.Duration
+}
+
+func NewKeyService(
+	keyRepo repository.SigningKeyRepository,
+	jwtManager *security.JWTManager,
+	securityService *SecurityService,
+	publishDelay time.Duration,
+) *KeyService {
+	return &KeyService{
+		keyRepo:         keyRepo,
+		jwtManager:      jwtManager,
+		securityService: securityService,
+		publishDelay:    publishDelay,
+	}
+}
+
+// Load kayıtlı anahtarları anahtarlığa yükler. Bir amaç için hiç anahtar
+// yoksa yapılandırmadaki anahtar başlangıç anahtarı olarak kaydedilir.
+func (s *KeyService) Load(ctx context.Context) error {
+	records, err := s.keyRepo.ListUsable(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	seeded := false
+	for _, bootstrap := range s.jwtManager.BootstrapKeys() {
+		if hasPurpose(records, bootstrap.Purpose) {
+			continue
+		}
+		if err := s.keyRepo.Create(ctx, &entity.SigningKey{
+			ID:          bootstrap.ID,
+			Purpose:     string(bootstrap.Purpose),
+			Algorithm:   bootstrap.Algorithm,
+			KeyMaterial: bootstrap.Material(),
+			ActivatesAt: time.Now(),
+			CreatedBy:   "system",
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			return err
+		}
+		seeded = true
+	}
+	if seeded {
+		if records, err = s.keyRepo.ListUsable(ctx, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]*security.Key, 0, len(records))
+	for _, record := range records {
+		key, err := security.NewKey(record.ID, security.KeyPurpose(record.Purpose), record.Algorithm, record.KeyMaterial, record.ActivatesAt, record.RetiresAt)
+		if err != nil {
+			return fmt.Errorf("anahtar %s yüklenemedi: %w", record.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	s.jwtManager.Keyring().Replace(keys)
+	return nil
+}
+
+// StartReload anahtarlığı belirtilen aralıkla veritabanından yeniler; başka
+// bir instance'ta yapılan rotasyonlar bu sayede yayılır
+func (s *KeyService) StartReload(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Load(ctx); err != nil {
+					log.Printf("imza anahtarları yenilenemedi: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Rotate amaç için yeni bir anahtar üretir. Yeni anahtar publishDelay sonra
+// imzalamaya başlar; mevcut anahtarlar o ana kadar imzalamaya, ardından
+// imzaladıkları son token'ın ömrü boyunca doğrulamaya devam eder.
+func (s *KeyService) Rotate(ctx context.Context, purpose security.KeyPurpose, rotatedBy string) (*entity.SigningKey, error) {
+	if purpose != security.KeyPurposeAccess && purpose != security.KeyPurposeRefresh && purpose != security.KeyPurposeMFA {
+		return nil, ErrInvalidKeyPurpose
+	}
+
+	algorithm := s.jwtManager.Algorithm(purpose)
+	material, err := security.GenerateKeyMaterial(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	activatesAt := time.Now().Add(s.publishDelay)
+	key, err := security.NewKey("", purpose, algorithm, material, activatesAt, nil)
+	if err != nil {
+		return nil, err
+	}
+	record := &entity.SigningKey{
+		ID:          key.ID,
+		Purpose:     string(purpose),
+		Algorithm:   algorithm,
+		KeyMaterial: material,
+		ActivatesAt: activatesAt,
+		CreatedBy:   rotatedBy,
+		CreatedAt:   time.Now(),
+	}
+
+	retiresAt := activatesAt.Add(s.jwtManager.MaxTokenTTL(purpose))
+	// Yeni anahtar eklenemezse eski anahtarlar da emekliye ayrılmamalıdır
+	if err := s.keyRepo.Rotate(ctx, string(purpose), retiresAt, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.securityService.RecordEvent(ctx, &entity.SecurityLog{
+		UserID: rotatedBy,
+		Action: entity.ActionKeyRotation,
+		Description: fmt.Sprintf("%s imza anahtarı döndürüldü: yeni anahtar %s, %s itibarıyla aktif; eski anahtarlar %s tarihinde emekliye ayrılır",
+			purpose, record.ID, activatesAt.Format(time.RFC3339), retiresAt.Format(time.RFC3339)),
+		CreatedBy: rotatedBy,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// ListKeys kullanımda olan anahtarların kayıtlarını döner (anahtar materyali JSON'a yazılmaz)
+func (s *KeyService) ListKeys(ctx context.Context) ([]entity.SigningKey, error) {
+	return s.keyRepo.ListUsable(ctx, time.Now())
+}
+
+func hasPurpose(records []entity.SigningKey, purpose security.KeyPurpose) bool {
+	for _, record := range records {
+		if record.Purpose == string(purpose) {
+			return true
+		}
+	}
+	return false
+}
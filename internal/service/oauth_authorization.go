@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/pkg/security"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRedirectURI      = errors.New("geçersiz veya istemci için kayıtlı olmayan redirect_uri")
+	ErrInvalidScope            = errors.New("istenen kapsama izin verilmiyor")
+	ErrUnsupportedResponseType = errors.New("yalnızca response_type=code desteklenir")
+	ErrPKCERequired            = errors.New("S256 yöntemiyle code_challenge gereklidir")
+	ErrInvalidGrant            = errors.New("authorization code veya refresh token geçersiz")
+	ErrAuthorizeNotConfigured  = errors.New("yetkilendirme giriş arayüzü (OAUTH_LOGIN_URL) tanımlı değil")
+)
+
+const (
+	// authorizationCodeSize authorization code'un rastgele bayt sayısı
+	authorizationCodeSize = 32
+	// PKCEMethodS256 desteklenen tek PKCE yöntemi; "plain" challenge'ı
+	// ele geçireni verifier'a sahip kılar
+	PKCEMethodS256 = "S256"
+)
+
+// reservedScopes servisin kendi kısıtlı token'larında kullanılır; istemcilere verilemez
+var reservedScopes = map[string]bool{
+	entity.ScopeUnverified:     true,
+	entity.ScopePasswordChange: true,
+}
+
+// AuthorizeRequest /oauth/authorize parametreleri (RFC 6749 4.1.1, RFC 7636 4.3)
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+// ValidateAuthorizeRequest istemciyi, yönlendirme adresini, PKCE'yi ve
+// kapsamı doğrular; verilecek kapsamı döner. ErrInvalidClient ve
+// ErrInvalidRedirectURI hatalarında istemciye yönlendirme yapılmamalıdır.
+func (s *OAuthService) ValidateAuthorizeRequest(ctx context.Context, req AuthorizeRequest) (*entity.OAuthClient, string, error) {
+	if req.ClientID == "" {
+		return nil, "", ErrInvalidClient
+	}
+	client, err := s.clientRepo.GetByID(ctx, req.ClientID)
+	if err != nil {
+		return nil, "", err
+	}
+	if client == nil || !client.IsActive {
+		return nil, "", ErrInvalidClient
+	}
+	if !containsString(client.RedirectURIs, req.RedirectURI) {
+		return client, "", ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != "code" {
+		return client, "", ErrUnsupportedResponseType
+	}
+	if req.CodeChallengeMethod != PKCEMethodS256 || !validPKCEValue(req.CodeChallenge, 43, 43) {
+		return client, "", ErrPKCERequired
+	}
+
+	scope, err := grantScope(client, req.Scope)
+	if err != nil {
+		return client, "", err
+	}
+	return client, scope, nil
+}
+
+// LoginRedirect doğrulanmış authorize isteğini, kullanıcının giriş yapıp onay
+// vereceği arayüze parametreleriyle birlikte iletir
+func (s *OAuthService) LoginRedirect(rawQuery string) (string, error) {
+	if s.config.LoginURL == "" {
+		return "", ErrAuthorizeNotConfigured
+	}
+	separator := "?"
+	if strings.Contains(s.config.LoginURL, "?") {
+		separator = "&"
+	}
+	return s.config.LoginURL + separator + rawQuery, nil
+}
+
+// Authorize giriş yapmış kullanıcı adına istemciye tek kullanımlık
+// authorization code verir ve code'un iletileceği adresi döner. İstemciler
+// kuruma ait olduğundan ayrıca onay ekranı gösterilmez.
+func (s *OAuthService) Authorize(ctx context.Context, userID string, req AuthorizeRequest) (string, error) {
+	client, scope, err := s.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+	if !user.IsActive {
+		return "", ErrUserBlocked
+	}
+
+	code, err := security.GenerateRandomToken(authorizationCodeSize)
+	if err != nil {
+		return "", err
+	}
+	if err := s.codeRepo.Save(ctx, code, &entity.AuthorizationCode{
+		ClientID:      client.ID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope,
+		CodeChallenge: req.CodeChallenge,
+		CreatedAt:     time.Now(),
+	}, s.config.CodeTTL); err != nil {
+		return "", err
+	}
+
+	if err := s.recordAudit(ctx, user.ID, entity.ActionOAuthAuthorize, "Uygulamaya erişim verildi: "+client.Name+" ("+scope+")"); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return AppendQuery(req.RedirectURI, params), nil
+}
+
+// ExchangeAuthorizationCode code'u, verildiği istemci, yönlendirme adresi ve
+// PKCE verifier eşleşirse token'a çevirir (RFC 6749 4.1.3)
+func (s *OAuthService) ExchangeAuthorizationCode(ctx context.Context, client *entity.OAuthClient, code, redirectURI, codeVerifier string) (*entity.OAuthTokenResponse, error) {
+	if code == "" {
+		return nil, ErrInvalidGrant
+	}
+	stored, err := s.codeRepo.Consume(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.ClientID != client.ID || stored.RedirectURI != redirectURI {
+		return nil, ErrInvalidGrant
+	}
+	if !verifyPKCE(codeVerifier, stored.CodeChallenge) {
+		return nil, ErrInvalidGrant
+	}
+
+	user, err := s.activeUser(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := RequestMetaFromContext(ctx)
+	session := &entity.Session{
+		UserID: user.ID,
+		Data: map[string]interface{}{
+			entity.SessionDevice:    client.Name,
+			entity.SessionUserAgent: truncate(meta.UserAgent, 255),
+			entity.SessionIP:        meta.IP,
+			entity.SessionCreatedAt: time.Now().UTC(),
+			entity.SessionClientID:  client.ID,
+			entity.SessionScope:     stored.Scope,
+		},
+	}
+	return s.issueClientTokens(ctx, user, client, uuid.New().String(), session, stored.Scope, "")
+}
+
+// RefreshClientToken istemciye ait refresh token'ı döndürür. Token başka
+// istemciye veya servisin kendi girişine aitse kabul edilmez; kullanılmış
+// token'ın tekrar sunulması oturumu sonlandırır. scope verilirse ilk verilen
+// kapsamın alt kümesi olmalıdır.
+func (s *OAuthService) RefreshClientToken(ctx context.Context, client *entity.OAuthClient, refreshToken, scope string) (*entity.OAuthTokenResponse, error) {
+	claims, err := s.jwtManager.ValidateToken(refreshToken, entity.RefreshToken)
+	if err != nil || claims.ClientID != client.ID || claims.SessionID == "" {
+		return nil, ErrInvalidGrant
+	}
+
+	key := sessionKey(claims.UserID, claims.SessionID)
+	session, err := s.sessionRepo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || sessionString(session, entity.SessionClientID) != client.ID {
+		return nil, ErrInvalidGrant
+	}
+
+	granted := sessionString(session, entity.SessionScope)
+	if scope == "" {
+		scope = claims.Scope
+	} else if !scopeSubset(scope, granted) {
+		return nil, ErrInvalidScope
+	}
+	// Eski refresh token ancak yenisi verilirse geçersiz olur; daraltılan
+	// kapsamda da offline_access korunur
+	if !scopeContains(scope, entity.ScopeOfflineAccess) {
+		scope += " " + entity.ScopeOfflineAccess
+	}
+
+	user, err := s.activeUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.issueClientTokens(ctx, user, client, claims.SessionID, session, normalizeScope(scope), security.HashToken(refreshToken))
+	if errors.Is(err, errRefreshTokenSuperseded) {
+		if err := s.securityService.RevokeReusedRefreshToken(ctx, claims); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidGrant
+	}
+	return response, err
+}
+
+// issueClientTokens istemci token'larını üretir; offline_access kapsamında
+// refresh token verilir ve oturumda yalnızca parmak izi tutulur. previousHash
+// AuthService.rotateSession'daki gibi yenilenen token'ın parmak izidir.
+func (s *OAuthService) issueClientTokens(ctx context.Context, user *entity.User, client *entity.OAuthClient, sessionID string, session *entity.Session, scope, previousHash string) (*entity.OAuthTokenResponse, error) {
+	withRefresh := scopeContains(scope, entity.ScopeOfflineAccess)
+	if !withRefresh {
+		// Refresh token yoksa oturum da yoktur; access token kendi süresiyle sona erer
+		sessionID = ""
+	}
+
+	tokens, err := s.jwtManager.GenerateClientTokens(user, sessionID, client.ID, scope, withRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if withRefresh {
+		ttl := s.jwtManager.RefreshTokenTTL()
+		session.Data[sessionRefreshTokenKey] = security.HashToken(tokens.RefreshToken)
+		session.Data[entity.SessionLastUsedAt] = time.Now().UTC()
+		session.ExpiresAt = time.Now().Add(ttl)
+		if err := saveSession(ctx, s.sessionRepo, sessionKey(user.ID, sessionID), session, previousHash, ttl); err != nil {
+			return nil, err
+		}
+	}
+
+	return &entity.OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.jwtManager.AccessTokenTTL().Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        scope,
+	}, nil
+}
+
+// activeUser token verilecek kullanıcının hâlâ etkin olduğunu doğrular
+func (s *OAuthService) activeUser(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, ErrInvalidGrant
+	}
+	return user, nil
+}
+
+func (s *OAuthService) recordAudit(ctx context.Context, userID string, action entity.AuditAction, details string) error {
+	meta := RequestMetaFromContext(ctx)
+	return s.auditRepo.Create(ctx, &entity.AuditLog{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Action:    action,
+		IP:        meta.IP,
+		UserAgent: truncate(meta.UserAgent, 255),
+		Status:    true,
+		Details:   details,
+		CreatedAt: time.Now(),
+	})
+}
+
+// grantScope istenen kapsamın istemciye izin verilenlerin içinde olduğunu
+// doğrular; kapsam istenmemişse izin verilenlerin tamamı verilir
+func grantScope(client *entity.OAuthClient, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return strings.Join(client.AllowedScopes, " "), nil
+	}
+	if !scopeSubset(requested, strings.Join(client.AllowedScopes, " ")) {
+		return "", ErrInvalidScope
+	}
+	return normalizeScope(requested), nil
+}
+
+// normalizeScope kapsam listesindeki tekrarları ve fazla boşlukları atar
+func normalizeScope(scope string) string {
+	seen := map[string]bool{}
+	var scopes []string
+	for _, item := range strings.Fields(scope) {
+		if !seen[item] {
+			seen[item] = true
+			scopes = append(scopes, item)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+func scopeSubset(requested, granted string) bool {
+	for _, item := range strings.Fields(requested) {
+		if !scopeContains(granted, item) {
+			return false
+		}
+	}
+	return true
+}
+
+func scopeContains(scope, item string) bool {
+	return containsString(strings.Fields(scope), item)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validScopeToken RFC 6749 3.3 scope-token sözdizimini ve ayrılmış kapsamları kontrol eder
+func validScopeToken(scope string) bool {
+	if scope == "" || reservedScopes[scope] {
+		return false
+	}
+	for _, r := range scope {
+		if r < 0x21 || r > 0x7e || r == '"' || r == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRedirectURI kayıt sırasında yönlendirme adresini kontrol eder:
+// https, yalnızca loopback için http veya mobil uygulamalar için ters alan
+// adı biçiminde özel şema (RFC 8252 7.1). Fragment içeremez.
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Fragment != "" || strings.Contains(raw, "#") {
+		return fmt.Errorf("%w: %q", ErrInvalidRedirectURI, raw)
+	}
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidRedirectURI, raw)
+		}
+	case "http":
+		host := u.Hostname()
+		if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+			return fmt.Errorf("%w: http yalnızca loopback adresleri için kullanılabilir", ErrInvalidRedirectURI)
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			return fmt.Errorf("%w: özel şema ters alan adı biçiminde olmalıdır", ErrInvalidRedirectURI)
+		}
+	}
+	return nil
+}
+
+// verifyPKCE verifier'ın S256 özetinin challenge ile eşleştiğini kontrol eder (RFC 7636 4.6)
+func verifyPKCE(verifier, challenge string) bool {
+	if !validPKCEValue(verifier, 43, 128) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// validPKCEValue verifier/challenge uzunluğunu ve karakter kümesini kontrol eder
+func validPKCEValue(value string, minLen, maxLen int) bool {
+	if len(value) < minLen || len(value) > maxLen {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// AppendQuery adresin mevcut sorgu parametrelerini koruyarak yenilerini ekler
+func AppendQuery(rawURL string, params url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
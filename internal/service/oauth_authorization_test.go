@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"auth-service/internal/domain/entity"
+)
+
+// stubClientRepository yalnızca GetByID'yi kullanan testler için bellekte istemci tutar
+type stubClientRepository struct {
+	clients map[string]*entity.OAuthClient
+}
+
+func (r *stubClientRepository) Create(ctx context.Context, client *entity.OAuthClient) error {
+	r.clients[client.ID] = client
+	return nil
+}
+
+func (r *stubClientRepository) GetByID(ctx context.Context, id string) (*entity.OAuthClient, error) {
+	return r.clients[id], nil
+}
+
+func (r *stubClientRepository) List(ctx context.Context) ([]entity.OAuthClient, error) {
+	var clients []entity.OAuthClient
+	for _, client := range r.clients {
+		clients = append(clients, *client)
+	}
+	return clients, nil
+}
+
+func (r *stubClientRepository) Update(ctx context.Context, client *entity.OAuthClient) error {
+	r.clients[client.ID] = client
+	return nil
+}
+
+func s256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	verifier := strings.Repeat("a", 43)
+
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{name: "S256 eşleşiyor", verifier: verifier, challenge: s256Challenge(verifier), want: true},
+		{name: "en uzun verifier", verifier: strings.Repeat("b", 128), challenge: s256Challenge(strings.Repeat("b", 128)), want: true},
+		{name: "plain challenge", verifier: verifier, challenge: verifier},
+		{name: "farklı verifier", verifier: strings.Repeat("c", 43), challenge: s256Challenge(verifier)},
+		{name: "kısa verifier", verifier: "short", challenge: s256Challenge("short")},
+		{name: "uzun verifier", verifier: strings.Repeat("d", 129), challenge: s256Challenge(strings.Repeat("d", 129))},
+		{name: "geçersiz karakter", verifier: strings.Repeat("e", 42) + "+", challenge: s256Challenge(strings.Repeat("e", 42) + "+")},
+		{name: "boş", verifier: "", challenge: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPKCE(tt.verifier, tt.challenge); got != tt.want {
+				t.Fatalf("verifyPKCE = %v, beklenen %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrantScope(t *testing.T) {
+	client := &entity.OAuthClient{AllowedScopes: []string{"openid", "profile", "email"}}
+
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+		wantErr   error
+	}{
+		{name: "istenmemiş", requested: "", want: "openid profile email"},
+		{name: "boşluklardan ibaret", requested: "   ", want: "openid profile email"},
+		{name: "alt küme", requested: "openid email", want: "openid email"},
+		{name: "tekrar ve fazla boşluk", requested: " email  email openid ", want: "email openid"},
+		{name: "izin verilenden geniş", requested: "openid admin", wantErr: ErrInvalidScope},
+		{name: "yalnızca izin verilmeyen", requested: "admin", wantErr: ErrInvalidScope},
+		{name: "önek eşleşmesi", requested: "profile:write", wantErr: ErrInvalidScope},
+		{name: "büyük harf", requested: "OPENID", wantErr: ErrInvalidScope},
+		{name: "ayrılmış kapsam", requested: entity.ScopePasswordChange, wantErr: ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := grantScope(client, tt.requested)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("hata = %v, beklenen %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("beklenmeyen hata: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("kapsam = %q, beklenen %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeSubset(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		granted   string
+		want      bool
+	}{
+		{name: "aynı", requested: "read write", granted: "read write", want: true},
+		{name: "alt küme", requested: "read", granted: "read write", want: true},
+		{name: "boş istek", requested: "", granted: "read", want: true},
+		{name: "geniş", requested: "read write", granted: "read"},
+		{name: "hiç verilmemiş", requested: "read", granted: ""},
+		{name: "alt dize", requested: "rea", granted: "read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeSubset(tt.requested, tt.granted); got != tt.want {
+				t.Fatalf("scopeSubset(%q, %q) = %v, beklenen %v", tt.requested, tt.granted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRedirectURI(t *testing.T) {
+	tests := []struct {
+		name  string
+		uri   string
+		valid bool
+	}{
+		{name: "https", uri: "https://app.example.com/callback", valid: true},
+		{name: "loopback http", uri: "http://127.0.0.1:8080/callback", valid: true},
+		{name: "localhost http", uri: "http://localhost/callback", valid: true},
+		{name: "IPv6 loopback http", uri: "http://[::1]:8080/callback", valid: true},
+		{name: "ters alan adı şeması", uri: "com.example.app:/callback", valid: true},
+		{name: "fragment", uri: "https://app.example.com/callback#frag"},
+		{name: "boş fragment", uri: "https://app.example.com/callback#"},
+		{name: "loopback olmayan http", uri: "http://app.example.com/callback"},
+		{name: "localhost alt alanı", uri: "http://localhost.evil.com/callback"},
+		{name: "hostsuz https", uri: "https:///callback"},
+		{name: "noktasız özel şema", uri: "myapp:/callback"},
+		{name: "javascript şeması", uri: "javascript:alert(1)"},
+		{name: "göreli", uri: "/callback"},
+		{name: "boş", uri: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRedirectURI(tt.uri)
+			if tt.valid && err != nil {
+				t.Fatalf("beklenmeyen hata: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidRedirectURI) {
+				t.Fatalf("ErrInvalidRedirectURI bekleniyordu, alınan: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAuthorizeRequest(t *testing.T) {
+	const redirectURI = "https://app.example.com/callback"
+	verifier := strings.Repeat("v", 43)
+
+	repo := &stubClientRepository{clients: map[string]*entity.OAuthClient{
+		"app": {
+			ID:            "app",
+			RedirectURIs:  []string{redirectURI},
+			AllowedScopes: []string{"openid", "profile"},
+			IsActive:      true,
+		},
+		"disabled": {
+			ID:            "disabled",
+			RedirectURIs:  []string{redirectURI},
+			AllowedScopes: []string{"openid"},
+		},
+	}}
+	s := NewOAuthService(repo, nil, nil, nil, nil, nil, nil, AuthorizationServerConfig{})
+
+	valid := func() AuthorizeRequest {
+		return AuthorizeRequest{
+			ResponseType:        "code",
+			ClientID:            "app",
+			RedirectURI:         redirectURI,
+			Scope:               "openid",
+			CodeChallenge:       s256Challenge(verifier),
+			CodeChallengeMethod: PKCEMethodS256,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(*AuthorizeRequest)
+		wantScope string
+		wantErr   error
+	}{
+		{name: "geçerli", modify: func(r *AuthorizeRequest) {}, wantScope: "openid"},
+		{name: "kapsam istenmemiş", modify: func(r *AuthorizeRequest) { r.Scope = "" }, wantScope: "openid profile"},
+		{name: "client_id yok", modify: func(r *AuthorizeRequest) { r.ClientID = "" }, wantErr: ErrInvalidClient},
+		{name: "bilinmeyen istemci", modify: func(r *AuthorizeRequest) { r.ClientID = "unknown" }, wantErr: ErrInvalidClient},
+		{name: "pasif istemci", modify: func(r *AuthorizeRequest) { r.ClientID = "disabled" }, wantErr: ErrInvalidClient},
+		{name: "redirect_uri fazladan yol", modify: func(r *AuthorizeRequest) { r.RedirectURI = redirectURI + "/extra" }, wantErr: ErrInvalidRedirectURI},
+		{name: "redirect_uri sorgu eklenmiş", modify: func(r *AuthorizeRequest) { r.RedirectURI = redirectURI + "?next=evil" }, wantErr: ErrInvalidRedirectURI},
+		{name: "redirect_uri fragment", modify: func(r *AuthorizeRequest) { r.RedirectURI = redirectURI + "#frag" }, wantErr: ErrInvalidRedirectURI},
+		{name: "redirect_uri sondaki eğik çizgi", modify: func(r *AuthorizeRequest) { r.RedirectURI = redirectURI + "/" }, wantErr: ErrInvalidRedirectURI},
+		{name: "redirect_uri yok", modify: func(r *AuthorizeRequest) { r.RedirectURI = "" }, wantErr: ErrInvalidRedirectURI},
+		{name: "response_type token", modify: func(r *AuthorizeRequest) { r.ResponseType = "token" }, wantErr: ErrUnsupportedResponseType},
+		{name: "PKCE yok", modify: func(r *AuthorizeRequest) { r.CodeChallenge, r.CodeChallengeMethod = "", "" }, wantErr: ErrPKCERequired},
+		{name: "PKCE plain", modify: func(r *AuthorizeRequest) { r.CodeChallenge, r.CodeChallengeMethod = verifier, "plain" }, wantErr: ErrPKCERequired},
+		{name: "PKCE yöntemi belirtilmemiş", modify: func(r *AuthorizeRequest) { r.CodeChallengeMethod = "" }, wantErr: ErrPKCERequired},
+		{name: "PKCE challenge kısa", modify: func(r *AuthorizeRequest) { r.CodeChallenge = "abc" }, wantErr: ErrPKCERequired},
+		{name: "kapsam izin verilenden geniş", modify: func(r *AuthorizeRequest) { r.Scope = "openid admin" }, wantErr: ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			_, scope, err := s.ValidateAuthorizeRequest(context.Background(), req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("hata = %v, beklenen %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("beklenmeyen hata: %v", err)
+			}
+			if scope != tt.wantScope {
+				t.Fatalf("kapsam = %q, beklenen %q", scope, tt.wantScope)
+			}
+		})
+	}
+}
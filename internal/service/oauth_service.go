@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/repository"
+	"auth-service/pkg/security"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidClient  = errors.New("istemci kimlik doğrulaması başarısız")
+	ErrClientNotFound = errors.New("istemci bulunamadı")
+	// ErrTokenNotOwned RFC 7009 2.1: istemci yalnızca kendisine verilen token'ı iptal edebilir
+	ErrTokenNotOwned        = errors.New("token bu istemciye verilmemiş")
+	ErrPublicResourceServer = errors.New("public istemci kaynak sunucusu olamaz")
+)
+
+// RFC 7662 / RFC 7009 token_type_hint değerleri
+const (
+	TokenTypeHintAccess  = "access_token"
+	TokenTypeHintRefresh = "refresh_token"
+)
+
+// IntrospectionResponse RFC 7662 introspection yanıtıdır; aktif olmayan
+// token'lar için yalnızca active=false döner
+type IntrospectionResponse struct {
+	Active    bool        `json:"active"`
+	Subject   string      `json:"sub,omitempty"`
+	Role      entity.Role `json:"role,omitempty"`
+	Scope     string      `json:"scope,omitempty"`
+	ExpiresAt int64       `json:"exp,omitempty"`
+	IssuedAt  int64       `json:"iat,omitempty"`
+	Issuer    string      `json:"iss,omitempty"`
+	TokenID   string      `json:"jti,omitempty"`
+	TokenType string      `json:"token_type,omitempty"`
+	SessionID string      `json:"sid,omitempty"`
+	ClientID  string      `json:"client_id,omitempty"`
+}
+
+// AuthorizationServerConfig authorization code akışının ayarları
+type AuthorizationServerConfig struct {
+	// CodeTTL authorization code'un token'a çevrilebileceği süre
+	CodeTTL time.Duration
+	// LoginURL /oauth/authorize isteklerinin, kullanıcı giriş yapıp onay
+	// vermesi için yönlendirildiği arayüz adresi
+	LoginURL string
+}
+
+// OAuthService OAuth istemcilerini, istemcilere açık token introspection /
+// iptal işlemlerini ve authorization code akışını yönetir
+type OAuthService struct {
+	clientRepo      repository.OAuthClientRepository
+	codeRepo        repository.AuthorizationCodeRepository
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	auditRepo       repository.AuditRepository
+	jwtManager      *security.JWTManager
+	securityService *SecurityService
+	config          AuthorizationServerConfig
+}
+
+func NewOAuthService(
+	clientRepo repository.OAuthClientRepository,
+	codeRepo repository.AuthorizationCodeRepository,
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	auditRepo repository.AuditRepository,
+	jwtManager *security.JWTManager,
+	securityService *SecurityService,
+	config AuthorizationServerConfig,
+) *OAuthService {
+	return &OAuthService{
+		clientRepo:      clientRepo,
+		codeRepo:        codeRepo,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		auditRepo:       auditRepo,
+		jwtManager:      jwtManager,
+		securityService: securityService,
+		config:          config,
+	}
+}
+
+// OAuthClientInput yeni istemci bilgileri; yalnızca introspection/iptal
+// kullanan servis istemcilerinde yönlendirme adresi ve kapsam gerekmez
+type OAuthClientInput struct {
+	Name           string   `json:"name"`
+	RedirectURIs   []string `json:"redirect_uris"`
+	AllowedScopes  []string `json:"allowed_scopes"`
+	Public         bool     `json:"public"`
+	ResourceServer bool     `json:"resource_server"`
+}
+
+// CreateClient yeni bir istemci oluşturur; secret yalnızca bu çağrıda döner.
+// Public istemcilere secret verilmez.
+func (s *OAuthService) CreateClient(ctx context.Context, input OAuthClientInput, createdBy string) (*entity.OAuthClient, string, error) {
+	for _, redirectURI := range input.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, scope := range input.AllowedScopes {
+		if !validScopeToken(scope) {
+			return nil, "", fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+	if input.Public && len(input.RedirectURIs) == 0 {
+		return nil, "", ErrInvalidRedirectURI
+	}
+	if input.Public && input.ResourceServer {
+		return nil, "", ErrPublicResourceServer
+	}
+
+	var secret, secretHash string
+	if !input.Public {
+		var err error
+		secret, err = security.GenerateRandomToken(32)
+		if err != nil {
+			return nil, "", err
+		}
+		secretHash = security.HashToken(secret)
+	}
+
+	client := &entity.OAuthClient{
+		ID:               uuid.New().String(),
+		Name:             input.Name,
+		SecretHash:       secretHash,
+		IsPublic:         input.Public,
+		RedirectURIs:     nonNil(input.RedirectURIs),
+		AllowedScopes:    nonNil(input.AllowedScopes),
+		IsResourceServer: input.ResourceServer,
+		IsActive:         true,
+		CreatedBy:        createdBy,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+func (s *OAuthService) ListClients(ctx context.Context) ([]entity.OAuthClient, error) {
+	return s.clientRepo.List(ctx)
+}
+
+// DeactivateClient istemcinin kimlik bilgilerini geçersiz kılar ve istemciye
+// verilmiş oturumları sonlandırır. Oturumsuz access token'lar introspection'da
+// istemci etkin olmadığı için aktif görünmez.
+func (s *OAuthService) DeactivateClient(ctx context.Context, clientID string) error {
+	client, err := s.clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return ErrClientNotFound
+	}
+
+	client.IsActive = false
+	client.UpdatedAt = time.Now()
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return err
+	}
+
+	sessions, err := s.sessionRepo.ListClientSessions(ctx, client.ID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.securityService.RevokeSession(ctx, session.UserID, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AuthenticateClient client_id / client_secret çiftini doğrular
+func (s *OAuthService) AuthenticateClient(ctx context.Context, clientID, secret string) (*entity.OAuthClient, error) {
+	if clientID == "" || secret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil || !client.IsActive || client.IsPublic || !security.CheckTokenHash(secret, client.SecretHash) {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+// AuthenticateTokenClient token ucundaki istemciyi doğrular: secret
+// verilmişse gizli istemci olarak, verilmemişse public istemci olarak.
+// Public istemcilerin kimliği PKCE ile kanıtlanır.
+func (s *OAuthService) AuthenticateTokenClient(ctx context.Context, clientID, secret string) (*entity.OAuthClient, error) {
+	if secret != "" {
+		return s.AuthenticateClient(ctx, clientID, secret)
+	}
+	if clientID == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil || !client.IsActive || !client.IsPublic {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+// Introspect token'ın imzası, süresi, iptal durumu ve (refresh token'lar
+// için) oturumdaki güncel token olup olmadığına bakarak durumunu döner.
+// Kaynak sunucusu olmayan istemciler başka istemcilere veya servisin kendi
+// girişine ait token'ları aktif olmayan token olarak görür.
+func (s *OAuthService) Introspect(ctx context.Context, client *entity.OAuthClient, token, tokenTypeHint string) (*IntrospectionResponse, error) {
+	claims, err := s.validate(ctx, token, tokenTypeHint)
+	if err != nil || claims == nil {
+		return &IntrospectionResponse{Active: false}, err
+	}
+	if !client.IsResourceServer && claims.ClientID != client.ID {
+		return &IntrospectionResponse{Active: false}, nil
+	}
+
+	response := &IntrospectionResponse{
+		Active:    true,
+		Subject:   claims.UserID,
+		Role:      claims.Role,
+		Scope:     claims.Scope,
+		Issuer:    claims.Issuer,
+		TokenID:   claims.ID,
+		TokenType: tokenTypeName(claims.Type),
+		SessionID: claims.SessionID,
+		ClientID:  claims.ClientID,
+	}
+	if claims.ExpiresAt != nil {
+		response.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.IssuedAt = claims.IssuedAt.Unix()
+	}
+	return response, nil
+}
+
+// Revoke RFC 7009'a göre istemciye verilmiş token'ı iptal eder. Refresh
+// token iptali bağlı oturumu ve o oturumun access token'larını da
+// sonlandırır. Geçersiz veya zaten iptal edilmiş token'lar hata sayılmaz;
+// başka istemciye ait token'lar ErrTokenNotOwned ile reddedilir.
+func (s *OAuthService) Revoke(ctx context.Context, client *entity.OAuthClient, token, tokenTypeHint string) error {
+	claims, err := s.validate(ctx, token, tokenTypeHint)
+	if err != nil || claims == nil {
+		return err
+	}
+	if claims.ClientID != client.ID {
+		return ErrTokenNotOwned
+	}
+
+	if claims.Type == entity.RefreshToken && claims.SessionID != "" {
+		return s.securityService.RevokeSession(ctx, claims.UserID, claims.SessionID)
+	}
+	return s.securityService.RevokeToken(ctx, claims.ID)
+}
+
+// validate token'ı ipucundaki tipten başlayarak dener; aktif değilse nil döner
+func (s *OAuthService) validate(ctx context.Context, token, tokenTypeHint string) (*entity.TokenClaims, error) {
+	types := []entity.TokenType{entity.AccessToken, entity.RefreshToken}
+	if tokenTypeHint == TokenTypeHintRefresh {
+		types = []entity.TokenType{entity.RefreshToken, entity.AccessToken}
+	}
+
+	for _, tokenType := range types {
+		claims, err := s.jwtManager.ValidateToken(token, tokenType)
+		if err != nil {
+			continue
+		}
+
+		revoked, err := s.securityService.IsTokenRevoked(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, nil
+		}
+
+		// Devre dışı bırakılan istemcinin token'ları süresi dolmadan da geçersizdir
+		if claims.ClientID != "" {
+			client, err := s.clientRepo.GetByID(ctx, claims.ClientID)
+			if err != nil {
+				return nil, err
+			}
+			if client == nil || !client.IsActive {
+				return nil, nil
+			}
+		}
+
+		if tokenType == entity.RefreshToken {
+			current, err := s.isCurrentRefreshToken(ctx, claims, token)
+			if err != nil || !current {
+				return nil, err
+			}
+		}
+		return claims, nil
+	}
+
+	return nil, nil
+}
+
+// isCurrentRefreshToken refresh token'ın oturumu hâlâ açık ve rotasyonla
+// yerine yenisi verilmemiş mi kontrol eder
+func (s *OAuthService) isCurrentRefreshToken(ctx context.Context, claims *entity.TokenClaims, token string) (bool, error) {
+	if claims.SessionID == "" {
+		return false, nil
+	}
+
+	session, err := s.sessionRepo.Get(ctx, sessionKey(claims.UserID, claims.SessionID))
+	if err != nil || session == nil {
+		return false, err
+	}
+
+	current, _ := session.Data[sessionRefreshTokenKey].(string)
+	return current == security.HashToken(token), nil
+}
+
+func tokenTypeName(tokenType entity.TokenType) string {
+	if tokenType == entity.RefreshToken {
+		return TokenTypeHintRefresh
+	}
+	return TokenTypeHintAccess
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"strings"
+)
+
+// RequestMeta handler'lardan servis çağrılarına taşınan istek bilgileri
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta istek bilgilerini context'e ekler
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext context'teki istek bilgilerini döner; yoksa boş değer döner
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+// describeDevice User-Agent'tan oturum listesinde gösterilecek kısa bir cihaz tanımı üretir
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Bilinmeyen cihaz"
+	}
+
+	platform := "Bilinmeyen platform"
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ios"):
+		platform = "iOS"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	client := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "chrome/"):
+		client = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		client = "Safari"
+	case strings.Contains(ua, "okhttp"), strings.Contains(ua, "cfnetwork"), strings.Contains(ua, "dart"):
+		client = "Mobil uygulama"
+	}
+
+	if client == "" {
+		return platform
+	}
+	return client + " / " + platform
+}
@@ -2,29 +2,65 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"auth-service/internal/domain/entity"
+
+	"github.com/google/uuid"
 )
 
+var ErrUserBlocked = errors.New("hesap engellenmiş")
+
+// LockoutPolicy başarısız girişlerde uygulanacak bekleme ve kilit kuralları
+type LockoutPolicy struct {
+	Threshold    int           // Bu sayıda ardışık hatadan sonra hesap kilitlenir
+	LockDuration time.Duration // Geçici kilit süresi
+	BaseDelay    time.Duration // İlk hatadan sonraki bekleme, her hatada ikiye katlanır
+	MaxDelay     time.Duration
+}
+
+// LockoutError hesabın geçici olarak kilitli olduğunu ya da bir sonraki
+// denemenin beklemesi gerektiğini bildirir
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("çok fazla başarısız deneme, %d saniye sonra tekrar deneyin", int(math.Ceil(e.RetryAfter().Seconds())))
+}
+
+// RetryAfter bir sonraki denemeye kadar kalan süre
+func (e *LockoutError) RetryAfter() time.Duration {
+	return time.Until(e.Until)
+}
+
 type SecurityService struct {
 	userRepo     repository.UserRepository
 	securityRepo repository.SecurityRepository
 	sessionRepo  repository.SessionRepository
+	tokenRepo    repository.TokenRepository
 	monitoring   *MonitoringService
+	lockout      LockoutPolicy
 }
 
 func NewSecurityService(
 	userRepo repository.UserRepository,
 	securityRepo repository.SecurityRepository,
 	sessionRepo repository.SessionRepository,
+	tokenRepo repository.TokenRepository,
 	monitoring *MonitoringService,
+	lockout LockoutPolicy,
 ) *SecurityService {
 	return &SecurityService{
 		userRepo:     userRepo,
 		securityRepo: securityRepo,
 		sessionRepo:  sessionRepo,
+		tokenRepo:    tokenRepo,
 		monitoring:   monitoring,
+		lockout:      lockout,
 	}
 }
 
@@ -33,6 +69,9 @@ func (s *SecurityService) BlockUser(ctx context.Context, userID, blockedBy, reas
 	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrUserNotFound
+	}
 
 	now := time.Now()
 	user.IsActive = false
@@ -40,25 +79,23 @@ func (s *SecurityService) BlockUser(ctx context.Context, userID, blockedBy, reas
 	user.BlockedBy = blockedBy
 	user.BlockReason = reason
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.userRepo.UpdateFields(ctx, user, "IsActive", "BlockedAt", "BlockedBy", "BlockReason"); err != nil {
 		return err
 	}
 
-	// Kullanıcının tüm oturumlarını sonlandır
-	if err := s.sessionRepo.DeleteAllUserSessions(ctx, userID); err != nil {
+	// Kullanıcının tüm oturumlarını ve access token'larını sonlandır
+	if err := s.RevokeUserTokens(ctx, userID); err != nil {
 		return err
 	}
 
 	// Güvenlik logu oluştur
-	secLog := &entity.SecurityLog{
+	return s.RecordEvent(ctx, &entity.SecurityLog{
 		UserID:      userID,
 		Action:      entity.ActionBlockUser,
 		Description: reason,
 		CreatedBy:   blockedBy,
 		CreatedAt:   now,
-	}
-
-	return s.securityRepo.CreateLog(ctx, secLog)
+	})
 }
 
 func (s *SecurityService) UnblockUser(ctx context.Context, userID, unblockBy string) error {
@@ -66,37 +103,249 @@ func (s *SecurityService) UnblockUser(ctx context.Context, userID, unblockBy str
 	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrUserNotFound
+	}
 
 	user.IsActive = true
 	user.BlockedAt = nil
 	user.BlockedBy = ""
 	user.BlockReason = ""
+	user.FailedLoginAttempts = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.userRepo.UpdateFields(ctx, user, "IsActive", "BlockedAt", "BlockedBy", "BlockReason",
+		"FailedLoginAttempts", "LastFailedLoginAt", "LockedUntil"); err != nil {
 		return err
 	}
 
-	secLog := &entity.SecurityLog{
+	return s.RecordEvent(ctx, &entity.SecurityLog{
 		UserID:    userID,
 		Action:    entity.ActionUnblockUser,
 		CreatedBy: unblockBy,
-		CreatedAt: time.Now(),
+	})
+}
+
+// RevokeUserTokens kullanıcının tüm oturumlarını siler ve bu ana kadar
+// üretilmiş access token'larını geçersiz kılar
+func (s *SecurityService) RevokeUserTokens(ctx context.Context, userID string) error {
+	if err := s.tokenRepo.RevokeAllForUser(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteAllUserSessions(ctx, userID)
+}
+
+// RevokeSession tek bir oturumu ve o oturuma bağlı access token'ları iptal eder
+func (s *SecurityService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.sessionRepo.Delete(ctx, sessionKey(userID, sessionID)); err != nil {
+		return err
+	}
+	return s.tokenRepo.Revoke(ctx, sessionID)
+}
+
+// RevokeToken tek bir access token'ı jti üzerinden iptal eder
+func (s *SecurityService) RevokeToken(ctx context.Context, tokenID string) error {
+	if tokenID == "" {
+		return nil
+	}
+	return s.tokenRepo.Revoke(ctx, tokenID)
+}
+
+// RevokeOtherSessions kullanıcının belirtilen oturum dışındaki tüm oturumlarını
+// iptal eder ve iptal edilen oturum sayısını döner
+func (s *SecurityService) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	sessions, err := s.sessionRepo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.RevokeSession(ctx, userID, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// IsTokenRevoked token'ın jti, oturum veya kullanıcı bazında iptal edilip edilmediğini kontrol eder
+func (s *SecurityService) IsTokenRevoked(ctx context.Context, claims *entity.TokenClaims) (bool, error) {
+	revoked, err := s.tokenRepo.IsRevoked(ctx, claims.ID, claims.SessionID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	revokedAt, err := s.tokenRepo.GetUserRevokedAt(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	if revokedAt != nil && !issuedAfter(claims, *revokedAt) {
+		return true, nil
 	}
 
+	return false, nil
+}
+
+// issuedAfter token'ın kullanıcı bazlı iptalden sonra verildiğini milisaniye
+// hassasiyetiyle kontrol eder. iat_ms taşımayan eski token'lar iptalle aynı
+// saniyede verildiyse iptal edilmiş sayılır.
+func issuedAfter(claims *entity.TokenClaims, revokedAt time.Time) bool {
+	if claims.IssuedAtMs > 0 {
+		return claims.IssuedAtMs > revokedAt.UnixMilli()
+	}
+	return claims.IssuedAt != nil && claims.IssuedAt.Unix() > revokedAt.Unix()
+}
+
+// RecordEvent güvenlik logu oluşturur; IP ve User-Agent verilmemişse context'ten alınır
+func (s *SecurityService) RecordEvent(ctx context.Context, secLog *entity.SecurityLog) error {
+	if secLog.ID == "" {
+		secLog.ID = uuid.New().String()
+	}
+	meta := RequestMetaFromContext(ctx)
+	if secLog.IP == "" {
+		secLog.IP = meta.IP
+	}
+	if secLog.UserAgent == "" {
+		secLog.UserAgent = truncate(meta.UserAgent, 255)
+	}
+	if secLog.CreatedAt.IsZero() {
+		secLog.CreatedAt = time.Now()
+	}
 	return s.securityRepo.CreateLog(ctx, secLog)
 }
 
-func (s *SecurityService) CheckSuspiciousActivity(ctx context.Context, userID string) error {
-	// Son başarısız giriş denemelerini kontrol et
-	user, err := s.userRepo.GetByID(ctx, userID)
+// RevokeReusedRefreshToken oturumun artık güncel olmayan bir refresh token'la
+// yenilenmeye çalışıldığını kaydeder ve oturumu sonlandırır; token çalınmış
+// olabileceğinden meşru sahibinin token'ı da geçersiz olur
+func (s *SecurityService) RevokeReusedRefreshToken(ctx context.Context, claims *entity.TokenClaims) error {
+	if err := s.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil {
+		return err
+	}
+
+	description := "Kullanılmış refresh token tekrar sunuldu"
+	if claims.ClientID != "" {
+		description += " (istemci " + claims.ClientID + ")"
+	}
+	return s.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      claims.UserID,
+		Action:      entity.ActionSuspicious,
+		Description: description + ", oturum " + claims.SessionID + " iptal edildi",
+		CreatedBy:   "system",
+	})
+}
+
+// CheckLoginAllowed engellenmiş, kilitli veya bekleme süresi dolmamış
+// hesaplarda giriş denemesini şifre kontrolünden önce reddeder
+func (s *SecurityService) CheckLoginAllowed(user *entity.User) error {
+	if !user.IsActive {
+		return ErrUserBlocked
+	}
+
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return &LockoutError{Until: *user.LockedUntil}
+	}
+
+	// Her başarısız denemede bekleme süresi ikiye katlanır
+	if user.FailedLoginAttempts > 0 && user.LastFailedLoginAt != nil {
+		nextAttempt := user.LastFailedLoginAt.Add(s.backoffDelay(user.FailedLoginAttempts))
+		if nextAttempt.After(now) {
+			return &LockoutError{Until: nextAttempt}
+		}
+	}
+
+	return nil
+}
+
+// maxBackoffDelay MaxDelay ve LockDuration tanımlı değilken beklemenin üst
+// sınırı; sayaç kilit sonrası sıfırlanmadığından sınırsız ikiye katlama taşar
+const maxBackoffDelay = 24 * time.Hour
+
+func (s *SecurityService) backoffDelay(attempts int) time.Duration {
+	if s.lockout.BaseDelay <= 0 {
+		return 0
+	}
+	limit := s.lockout.MaxDelay
+	if limit <= 0 {
+		limit = s.lockout.LockDuration
+	}
+	if limit <= 0 {
+		limit = maxBackoffDelay
+	}
+
+	delay := s.lockout.BaseDelay
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// RegisterFailedLogin başarısız bir giriş adımını kullanıcıya işler ve kilit
+// kurallarını uygular. Sayaç veritabanında atomik artırılır; paralel
+// denemeler birbirinin üzerine yazamaz ve yalnızca bu kolonlar güncellenir.
+func (s *SecurityService) RegisterFailedLogin(ctx context.Context, user *entity.User, reason string) error {
+	now := time.Now()
+	state, err := s.userRepo.IncrementFailedLogins(ctx, user.ID, now, s.lockout.Threshold, now.Add(s.lockout.LockDuration))
 	if err != nil {
 		return err
 	}
+	if state == nil {
+		return ErrUserNotFound
+	}
+	user.FailedLoginAttempts = state.FailedLoginAttempts
+	user.LastFailedLoginAt = state.LastFailedLoginAt
+	user.LockedUntil = state.LockedUntil
+	s.monitoring.RecordLoginAttempt(false)
 
-	if user.FailedLoginAttempts >= 5 {
-		// Hesabı otomatik olarak bloke et
-		return s.BlockUser(ctx, userID, "system", "Too many failed login attempts")
+	if err := s.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      user.ID,
+		Action:      entity.ActionFailedLogin,
+		Description: reason,
+		CreatedBy:   "system",
+	}); err != nil {
+		return err
 	}
 
+	return s.CheckSuspiciousActivity(ctx, user)
+}
+
+// RegisterSuccessfulLogin başarısız deneme sayaçlarını sıfırlar ve son giriş
+// bilgisini kaydeder; kullanıcının diğer kolonlarına dokunmaz
+func (s *SecurityService) RegisterSuccessfulLogin(ctx context.Context, user *entity.User) error {
+	now := time.Now()
+	ip := RequestMetaFromContext(ctx).IP
+	if err := s.userRepo.RecordSuccessfulLogin(ctx, user.ID, now, ip); err != nil {
+		return err
+	}
+
+	user.FailedLoginAttempts = 0
+	user.LastFailedLoginAt = nil
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+	user.LastLoginIP = ip
+	s.monitoring.RecordLoginAttempt(true)
 	return nil
 }
+
+// CheckSuspiciousActivity sayaç eşiğe ulaştığında RegisterFailedLogin'in
+// koyduğu kilidi güvenlik loguna yazar
+func (s *SecurityService) CheckSuspiciousActivity(ctx context.Context, user *entity.User) error {
+	if s.lockout.Threshold <= 0 || user.FailedLoginAttempts < s.lockout.Threshold || user.LockedUntil == nil {
+		return nil
+	}
+
+	return s.RecordEvent(ctx, &entity.SecurityLog{
+		UserID:      user.ID,
+		Action:      entity.ActionAccountLocked,
+		Description: fmt.Sprintf("%d başarısız denemeden sonra hesap %s tarihine kadar kilitlendi", user.FailedLoginAttempts, user.LockedUntil.Format(time.RFC3339)),
+		CreatedBy:   "system",
+	})
+}
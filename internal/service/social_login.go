@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"auth-service/internal/domain/entity"
+	"auth-service/internal/domain/oauth"
+	"auth-service/pkg/security"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrUnknownOAuthProvider = errors.New("sosyal giriş sağlayıcısı bulunamadı")
+	ErrInvalidOAuthState    = errors.New("geçersiz veya süresi dolmuş oturum açma isteği, lütfen tekrar deneyin")
+	ErrRedirectNotAllowed   = errors.New("yönlendirme adresine izin verilmiyor")
+	ErrOAuthEmailMissing    = errors.New("sağlayıcı email adresi döndürmedi")
+	ErrInvalidLinkToken     = errors.New("geçersiz veya süresi dolmuş hesap bağlama isteği")
+	ErrIdentityNotFound     = errors.New("bağlı hesap bulunamadı")
+	ErrLastLoginMethod      = errors.New("şifre belirlenmemiş hesabın son bağlı hesabı kaldırılamaz, önce şifre belirleyin")
+)
+
+// oauthStateSize sosyal giriş state değerinin rastgele bayt sayısı
+const oauthStateSize = 32
+
+// BeginOAuthLogin sağlayıcıya yönlendirmeden önce tek kullanımlık state ve
+// PKCE verifier ve OIDC nonce üretip saklar. Dönen state, isteği başlatan
+// tarayıcıya da bağlanmalıdır (handler bunu cookie ile yapar).
+func (s *AuthService) BeginOAuthLogin(ctx context.Context, providerName, redirectURI string) (authURL, state string, err error) {
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return "", "", ErrUnknownOAuthProvider
+	}
+	if redirectURI != "" && !s.redirectAllowed(redirectURI) {
+		return "", "", ErrRedirectNotAllowed
+	}
+
+	state, err = security.GenerateRandomToken(oauthStateSize)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := security.GenerateRandomToken(oauthStateSize)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	if err := s.oauthStateRepo.Save(ctx, state, &entity.OAuthState{
+		Provider:     provider.Name(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+		CreatedAt:    time.Now(),
+	}, s.config.OAuthStateTTL); err != nil {
+		return "", "", err
+	}
+
+	return provider.GetAuthURL(oauth.AuthRequest{State: state, CodeVerifier: verifier, Nonce: nonce}), state, nil
+}
+
+// HandleOAuthCallback state'i tüketir, code'u kayıtlı verifier ile token'a
+// çevirir ve kullanıcıyı giriş yaptırır. Başlangıçta verilen yönlendirme
+// adresi de döner.
+func (s *AuthService) HandleOAuthCallback(ctx context.Context, providerName, state, code string) (*LoginResult, string, error) {
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return nil, "", ErrUnknownOAuthProvider
+	}
+	if state == "" {
+		return nil, "", ErrInvalidOAuthState
+	}
+	stored, err := s.oauthStateRepo.Consume(ctx, state)
+	if err != nil {
+		return nil, "", err
+	}
+	// Bir sağlayıcı için başlatılan akış başka sağlayıcının callback'inde tamamlanamaz
+	if stored == nil || stored.Provider != provider.Name() {
+		return nil, "", ErrInvalidOAuthState
+	}
+
+	info, err := provider.GetUserInfo(ctx, code, oauth.AuthRequest{
+		State:        state,
+		CodeVerifier: stored.CodeVerifier,
+		Nonce:        stored.Nonce,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if info.ID == "" || info.Email == "" {
+		return nil, "", ErrOAuthEmailMissing
+	}
+
+	result, err := s.oauthLogin(ctx, info)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, stored.RedirectURI, nil
+}
+
+// oauthLogin sağlayıcı kimliğine bağlı hesapla giriş yapar. Bağlı hesap
+// yoksa ve email mevcut bir hesaba aitse hesaplar birleştirilmez; hesap
+// sahibinin şifresiyle onaylaması için bağlama token'ı döner. Hiç hesap
+// yoksa yeni hesap açılır.
+func (s *AuthService) oauthLogin(ctx context.Context, info *oauth.UserInfo) (*LoginResult, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, info.Provider, info.ID)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return s.socialLogin(ctx, user, info.Provider)
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, info.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		linkToken, err := security.GenerateRandomToken(oauthStateSize)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.oauthLinkRepo.Save(ctx, linkToken, &entity.PendingIdentityLink{
+			UserID:    existing.ID,
+			Provider:  info.Provider,
+			Subject:   info.ID,
+			Email:     info.Email,
+			CreatedAt: time.Now(),
+		}, s.config.OAuthStateTTL); err != nil {
+			return nil, err
+		}
+		return &LoginResult{LinkRequired: true, LinkToken: linkToken}, nil
+	}
+
+	user, err := s.createOAuthUser(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.createIdentity(ctx, user.ID, info.Provider, info.ID, info.Email); err != nil {
+		return nil, err
+	}
+	return s.socialLogin(ctx, user, info.Provider)
+}
+
+// LinkIdentity bekleyen bağlama isteğini hesap sahibinin şifresiyle onaylar,
+// sağlayıcı kimliğini hesaba bağlar ve giriş yaptırır. Hatalı şifre normal
+// girişteki gibi kilit sayacına işlenir.
+func (s *AuthService) LinkIdentity(ctx context.Context, linkToken, password string) (*LoginResult, error) {
+	link, err := s.oauthLinkRepo.Get(ctx, linkToken)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrInvalidLinkToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidLinkToken
+	}
+
+	if err := s.securityService.CheckLoginAllowed(user); err != nil {
+		return nil, s.loginFailed(ctx, user, err.Error(), false, err)
+	}
+	if user.PasswordUnusable || !s.passwordHasher.Verify(password, user.Password) {
+		return nil, s.loginFailed(ctx, user, "Hesap bağlama: geçersiz şifre", true, ErrInvalidCredentials)
+	}
+
+	if err := s.oauthLinkRepo.Delete(ctx, linkToken); err != nil {
+		return nil, err
+	}
+	if err := s.createIdentity(ctx, user.ID, link.Provider, link.Subject, link.Email); err != nil {
+		return nil, err
+	}
+	s.upgradePasswordHash(ctx, user, password)
+
+	return s.socialLogin(ctx, user, link.Provider)
+}
+
+// ListIdentities kullanıcıya bağlı sosyal giriş hesaplarını döner
+func (s *AuthService) ListIdentities(ctx context.Context, userID string) ([]entity.UserIdentity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+// UnlinkIdentity bağlı sosyal giriş hesabını kaldırır; şifresi olmayan
+// kullanıcının son giriş yöntemi kaldırılamaz
+func (s *AuthService) UnlinkIdentity(ctx context.Context, userID, identityID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	identities, err := s.identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var provider string
+	for _, identity := range identities {
+		if identity.ID == identityID {
+			provider = identity.Provider
+		}
+	}
+	if provider == "" {
+		return ErrIdentityNotFound
+	}
+	if user.PasswordUnusable && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	deleted, err := s.identityRepo.Delete(ctx, userID, identityID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+
+	return s.recordAudit(ctx, userID, entity.ActionIdentityUnlink, true, "Bağlı hesap kaldırıldı: "+provider)
+}
+
+func (s *AuthService) createIdentity(ctx context.Context, userID, provider, subject, email string) error {
+	if err := s.identityRepo.Create(ctx, &entity.UserIdentity{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+	return s.recordAudit(ctx, userID, entity.ActionIdentityLink, true, "Hesap bağlandı: "+provider)
+}
+
+// socialLogin sosyal giriş sonrası hesap durumunu kontrol eder; 2FA açık
+// hesaplar ikinci adıma yönlendirilir
+func (s *AuthService) socialLogin(ctx context.Context, user *entity.User, provider string) (*LoginResult, error) {
+	if err := s.securityService.CheckLoginAllowed(user); err != nil {
+		return nil, s.loginFailed(ctx, user, err.Error(), false, err)
+	}
+	if !user.IsVerified && s.config.EmailVerificationMode == EmailVerificationRequired {
+		return nil, s.loginFailed(ctx, user, "Email adresi doğrulanmamış", false, ErrEmailNotVerified)
+	}
+
+	if user.Is2FAEnabled {
+		mfaToken, err := s.jwtManager.GenerateMFAToken(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	tokens, err := s.completeLogin(ctx, user, "sosyal giriş ("+provider+")")
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{TokenPair: tokens}, nil
+}
+
+// createOAuthUser sosyal girişle gelen yeni kullanıcıyı açar; email doğrulama
+// durumu sağlayıcıdan alınır. Şifreyle giriş ancak şifre sıfırlama ile açılabilir.
+func (s *AuthService) createOAuthUser(ctx context.Context, info *oauth.UserInfo) (*entity.User, error) {
+	unusable, err := security.GenerateRandomToken(userTokenSize)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.passwordHasher.Hash(unusable)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &entity.User{
+		ID:               uuid.New().String(),
+		Email:            info.Email,
+		Password:         hashedPassword,
+		PasswordUnusable: true,
+		Role:             entity.RoleUser,
+		IsVerified:       info.EmailVerified,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// dropUnprovenIdentities sağlayıcının doğrulamadığı email'le sosyal girişten
+// açılmış hesabın bağlı hesaplarını ve oturumlarını siler. Başkasının
+// adresiyle önceden hesap açılmış olabilir; adresin sahibi email'i
+// doğruladığında veya şifre sıfırladığında hesabı açan kişinin erişimi kalmamalıdır.
+func (s *AuthService) dropUnprovenIdentities(ctx context.Context, user *entity.User) error {
+	if !user.PasswordUnusable || user.IsVerified {
+		return nil
+	}
+
+	deleted, err := s.identityRepo.DeleteAllForUser(ctx, user.ID)
+	if err != nil || deleted == 0 {
+		return err
+	}
+	if err := s.recordAudit(ctx, user.ID, entity.ActionIdentityUnlink, true, fmt.Sprintf("Email sahipliği kanıtlandı, doğrulanmamış adresle bağlanmış %d hesap kaldırıldı", deleted)); err != nil {
+		return err
+	}
+	return s.InvalidateTokens(ctx, user.ID)
+}
+
+// OAuthProviders etkin sosyal giriş sağlayıcılarının adları
+func (s *AuthService) OAuthProviders() []string {
+	return s.oauthProviders.Names()
+}
+
+// OAuthUsesFormPost sağlayıcının callback'i POST gövdesiyle yapıp yapmadığını
+// bildirir; bu durumda state cookie'si siteler arası POST'ta da gönderilmelidir
+func (s *AuthService) OAuthUsesFormPost(providerName string) bool {
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return false
+	}
+	poster, ok := provider.(oauth.FormPoster)
+	return ok && poster.UsesFormPost()
+}
+
+// OAuthStateTTL state kaydının geçerlilik süresi; state cookie'si de aynı süreyle yaşar
+func (s *AuthService) OAuthStateTTL() time.Duration {
+	return s.config.OAuthStateTTL
+}
+
+// redirectAllowed yönlendirme adresinin izin listesindeki bir adresle birebir
+// eşleşip eşleşmediğini kontrol eder; önek eşleşmesi açık yönlendirmeye yol açar
+func (s *AuthService) redirectAllowed(redirectURI string) bool {
+	for _, allowed := range s.config.OAuthRedirectAllowlist {
+		if redirectURI == allowed {
+			return true
+		}
+	}
+	return false
+}
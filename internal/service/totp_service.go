@@ -3,6 +3,7 @@ package service
 import (
 	"crypto/rand"
 	"encoding/base32"
+	"strings"
 
 	"github.com/pquerna/otp/totp"
 )
@@ -41,3 +42,23 @@ func (s *TOTPService) GenerateQRCode(email, secret string) (string, error) {
 func (s *TOTPService) ValidateCode(secret, code string) bool {
 	return totp.Validate(code, secret)
 }
+
+// GenerateRecoveryCodes "XXXXX-XXXXX" biçiminde rastgele kurtarma kodları üretir
+func (s *TOTPService) GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// NormalizeRecoveryCode kullanıcı girişindeki boşluk, tire ve harf farklarını giderir
+func (s *TOTPService) NormalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
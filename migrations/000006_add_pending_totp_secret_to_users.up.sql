@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN pending_totp_secret VARCHAR(32);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_email_verification_token;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verification_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verification_token;
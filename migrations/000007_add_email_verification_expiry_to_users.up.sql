@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN email_verification_token VARCHAR(100);
+ALTER TABLE users ADD COLUMN email_verification_expires_at TIMESTAMP;
+
+CREATE INDEX idx_users_email_verification_token ON users(email_verification_token);
@@ -0,0 +1,12 @@
+CREATE TABLE signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    purpose VARCHAR(20) NOT NULL,
+    algorithm VARCHAR(10) NOT NULL,
+    key_material TEXT NOT NULL,
+    activates_at TIMESTAMP NOT NULL,
+    retires_at TIMESTAMP,
+    created_by VARCHAR(36),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_signing_keys_purpose ON signing_keys(purpose);
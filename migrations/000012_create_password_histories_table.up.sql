@@ -0,0 +1,9 @@
+CREATE TABLE password_histories (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_histories_user_id ON password_histories(user_id, created_at DESC);
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP;
+
+-- Mevcut hesapların şifre yaşı dağıtım anından başlar; aksi halde azami yaş
+-- açıldığında tüm eski hesaplar aynı anda kısıtlanırdı
+UPDATE users SET password_changed_at = NOW();
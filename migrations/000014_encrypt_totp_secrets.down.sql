@@ -0,0 +1,3 @@
+-- Geri almadan önce değerler düz metne çevrilmiş olmalıdır
+ALTER TABLE users ALTER COLUMN pending_totp_secret TYPE VARCHAR(32);
+ALTER TABLE users ALTER COLUMN totp_secret TYPE VARCHAR(255);
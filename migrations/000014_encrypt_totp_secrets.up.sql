@@ -0,0 +1,4 @@
+-- Şifreli değerler eski VARCHAR(32) sınırına sığmaz; mevcut düz metin değerler
+-- go run ./cmd/reencrypt ile şifrelenir
+ALTER TABLE users ALTER COLUMN totp_secret TYPE TEXT;
+ALTER TABLE users ALTER COLUMN pending_totp_secret TYPE TEXT;
@@ -0,0 +1,9 @@
+-- Kolonlar 000001 ve 000007'deki tanımlarıyla geri eklenir
+ALTER TABLE users ADD COLUMN email_verification_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN email_verification_token VARCHAR(100);
+ALTER TABLE users ADD COLUMN token_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN password_reset_token VARCHAR(255);
+
+CREATE INDEX idx_users_email_verification_token ON users(email_verification_token);
+
+DROP TABLE IF EXISTS user_tokens;
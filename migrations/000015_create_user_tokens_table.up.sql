@@ -0,0 +1,18 @@
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    purpose VARCHAR(30) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_tokens_user_id ON user_tokens(user_id, purpose);
+
+-- Düz metin saklanan eski token'lar geçersiz kılınır; kullanıcılar yeniden talep eder
+ALTER TABLE users DROP COLUMN IF EXISTS password_reset_token;
+ALTER TABLE users DROP COLUMN IF EXISTS token_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verification_token;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verification_expires_at;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS password_unusable;
+
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,14 @@
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    provider VARCHAR(30) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities(provider, subject);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+ALTER TABLE users ADD COLUMN password_unusable BOOLEAN NOT NULL DEFAULT FALSE;
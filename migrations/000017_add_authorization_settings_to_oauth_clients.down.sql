@@ -0,0 +1,5 @@
+ALTER TABLE oauth_clients
+    DROP COLUMN is_resource_server,
+    DROP COLUMN allowed_scopes,
+    DROP COLUMN redirect_uris,
+    DROP COLUMN is_public;
@@ -0,0 +1,5 @@
+ALTER TABLE oauth_clients
+    ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN redirect_uris TEXT NOT NULL DEFAULT '[]',
+    ADD COLUMN allowed_scopes TEXT NOT NULL DEFAULT '[]',
+    ADD COLUMN is_resource_server BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,84 @@
+package security
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BreachRecordSize is the size of one record in a breach corpus file: a raw
+// SHA-1 digest
+const BreachRecordSize = sha1.Size
+
+// BreachCorpus is a locally stored list of breached password hashes in the
+// format produced by cmd/breachbuild: sorted, de-duplicated 20-byte SHA-1
+// digests without separators. Lookups binary search the file on disk, so
+// the corpus does not have to fit in memory.
+type BreachCorpus struct {
+	file    *os.File
+	records int64
+}
+
+// OpenBreachCorpus opens a corpus file built by cmd/breachbuild
+func OpenBreachCorpus(path string) (*BreachCorpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("sızıntı veri seti açılamadı: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size()%BreachRecordSize != 0 {
+		file.Close()
+		return nil, fmt.Errorf("sızıntı veri seti bozuk: boyut %d baytın katı değil", BreachRecordSize)
+	}
+
+	return &BreachCorpus{file: file, records: info.Size() / BreachRecordSize}, nil
+}
+
+// Contains reports whether the password appears in the corpus. A nil corpus
+// (screening disabled) never reports a match.
+func (c *BreachCorpus) Contains(password string) (bool, error) {
+	if c == nil || c.records == 0 {
+		return false, nil
+	}
+
+	digest := sha1.Sum([]byte(password))
+	record := make([]byte, BreachRecordSize)
+
+	var readErr error
+	index := sort.Search(int(c.records), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := c.file.ReadAt(record, int64(i)*BreachRecordSize); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(record, digest[:]) >= 0
+	})
+	if readErr != nil {
+		return false, fmt.Errorf("sızıntı veri seti okunamadı: %w", readErr)
+	}
+	if index >= int(c.records) {
+		return false, nil
+	}
+
+	if _, err := c.file.ReadAt(record, int64(index)*BreachRecordSize); err != nil {
+		return false, fmt.Errorf("sızıntı veri seti okunamadı: %w", err)
+	}
+	return bytes.Equal(record, digest[:]), nil
+}
+
+// Close releases the corpus file
+func (c *BreachCorpus) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.file.Close()
+}
@@ -0,0 +1,233 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envelopePrefix marks values written by Envelope; anything else is treated
+// as legacy plaintext so existing rows keep working until re-encrypted
+const envelopePrefix = "enc:v1:"
+
+// dataKeySize is the size of the per-value AES-256 data key
+const dataKeySize = 32
+
+var (
+	ErrUnknownKEK       = errors.New("şifreli değerin anahtar sürümü bulunamadı")
+	ErrInvalidEnvelope  = errors.New("şifreli değer bozuk")
+	ErrNoEncryptionKeys = errors.New("şifreleme anahtarı tanımlı değil")
+)
+
+// Envelope encrypts small secrets such as TOTP seeds or refresh tokens for
+// storage. Every value gets a fresh AES-256-GCM data key, which is itself
+// encrypted with a key-encryption key (KEK). The stored value records the
+// KEK version so KEKs can be rotated without losing older values:
+//
+//	enc:v1:<kek version>:<base64 wrapped data key>:<base64 ciphertext>
+//
+// Both layers are bound to associated data naming where the value is stored
+// (see AssociatedData), so a ciphertext copied to another row or column
+// fails to decrypt.
+type Envelope struct {
+	keks    map[string]cipher.AEAD
+	current string
+}
+
+// NewEnvelope creates an envelope from KEKs keyed by version; current is the
+// version used for new values
+func NewEnvelope(keks map[string][]byte, current string) (*Envelope, error) {
+	if len(keks) == 0 {
+		return nil, ErrNoEncryptionKeys
+	}
+	if _, ok := keks[current]; !ok {
+		return nil, fmt.Errorf("geçerli anahtar sürümü %q tanımlı değil", current)
+	}
+
+	envelope := &Envelope{keks: make(map[string]cipher.AEAD, len(keks)), current: current}
+	for version, kek := range keks {
+		if len(kek) != 32 {
+			return nil, fmt.Errorf("anahtar %q 32 bayt olmalıdır, %d bayt", version, len(kek))
+		}
+		aead, err := newGCM(kek)
+		if err != nil {
+			return nil, err
+		}
+		envelope.keks[version] = aead
+	}
+	return envelope, nil
+}
+
+// LoadEnvelope builds an envelope from a key list given inline or, when path
+// is set, read from a file. Entries are "<version>:<base64 32-byte key>"
+// separated by commas or newlines; current defaults to the last entry.
+func LoadEnvelope(spec, path, current string) (*Envelope, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("şifreleme anahtarları okunamadı: %w", err)
+		}
+		spec = string(data)
+	}
+
+	keks := map[string][]byte{}
+	last := ""
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		version, encoded, ok := strings.Cut(entry, ":")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("geçersiz anahtar tanımı, <sürüm>:<base64 anahtar> bekleniyor")
+		}
+		kek, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("anahtar %q base64 değil: %w", version, err)
+		}
+		keks[version] = kek
+		last = version
+	}
+
+	if current == "" {
+		current = last
+	}
+	return NewEnvelope(keks, current)
+}
+
+// CurrentVersion returns the KEK version used for new values
+func (e *Envelope) CurrentVersion() string {
+	return e.current
+}
+
+// AssociatedData identifies the row and column an encrypted value belongs to
+func AssociatedData(table, column, rowID string) []byte {
+	return []byte(table + "." + column + ":" + rowID)
+}
+
+// Encrypt seals plaintext under a fresh data key, bound to associatedData.
+// Empty strings are stored as-is so "not set" checks keep working on the column.
+func (e *Envelope) Encrypt(plaintext string, associatedData []byte) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	wrappedKey, err := seal(e.keks[e.current], dataKey, associatedData)
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), associatedData)
+	if err != nil {
+		return "", err
+	}
+
+	return envelopePrefix + e.current + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value written by Encrypt with the same associatedData.
+// Values without the envelope prefix are legacy plaintext and returned unchanged.
+func (e *Envelope) Decrypt(value string, associatedData []byte) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	version, wrappedKey, ciphertext, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	kek, ok := e.keks[version]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKEK, version)
+	}
+
+	dataKey, err := open(kek, wrappedKey, associatedData)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, ciphertext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencrypt reports whether a stored value is plaintext or sealed with
+// a KEK other than the current one
+func (e *Envelope) NeedsReencrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	version, _, _, err := parseEnvelope(value)
+	return err == nil && version != e.current
+}
+
+// IsEncrypted reports whether the value was written by an Envelope
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+func parseEnvelope(value string) (version string, wrappedKey, ciphertext []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrInvalidEnvelope
+	}
+	if wrappedKey, err = base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrInvalidEnvelope
+	}
+	if ciphertext, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrInvalidEnvelope
+	}
+	return parts[0], wrappedKey, ciphertext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts with a random nonce and prepends it to the ciphertext
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	return plaintext, nil
+}
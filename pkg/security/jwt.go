@@ -1,12 +1,16 @@
 package security
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
+	"os"
 	"time"
 
 	"auth-service/internal/domain/entity"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTConfig struct {
@@ -14,26 +18,137 @@ type JWTConfig struct {
 	RefreshTokenSecret string
 	AccessTokenTTL     time.Duration
 	RefreshTokenTTL    time.Duration
-	Issuer             string
+	MFATokenTTL        time.Duration
+	// MFATokenSecret signs two-factor challenge tokens. When empty a secret
+	// is derived from RefreshTokenSecret.
+	MFATokenSecret string
+	Issuer         string
+	// Audience is the "aud" of access tokens that grant API access. Tokens
+	// restricted to this service (unverified, password_change) carry none.
+	Audience string
+	// SigningAlgorithm selects how access tokens are signed: HS256 (default,
+	// shared secret) or RS256 / ES256 / EdDSA with PrivateKeyPath. Refresh
+	// and MFA tokens are only verified by this service and stay on HS256.
+	SigningAlgorithm string
+	PrivateKeyPath   string
 }
 
+// AccessTokenJWTType is the "typ" header of access tokens (RFC 9068). Services
+// verifying access tokens against the JWKS must check it together with "iss"
+// and "aud"; other tokens signed by this service lack one or the other.
+const AccessTokenJWTType = "at+jwt"
+
 type JWTManager struct {
-	config JWTConfig
+	config    JWTConfig
+	keyring   *Keyring
+	bootstrap []*Key
+	// legacyKeyIDs maps tokens issued before key IDs were introduced (no
+	// "kid" header) to the key configured for their purpose
+	legacyKeyIDs map[KeyPurpose]string
+}
+
+func NewJWTManager(config JWTConfig) (*JWTManager, error) {
+	if config.SigningAlgorithm == "" {
+		config.SigningAlgorithm = AlgHS256
+	}
+
+	accessMaterial := []byte(config.AccessTokenSecret)
+	switch config.SigningAlgorithm {
+	case AlgHS256:
+	case AlgRS256, AlgES256, AlgEdDSA:
+		data, err := os.ReadFile(config.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("imza anahtarı okunamadı: %w", err)
+		}
+		accessMaterial = data
+	default:
+		return nil, fmt.Errorf("desteklenmeyen imza algoritması: %s", config.SigningAlgorithm)
+	}
+
+	// Yapılandırmadaki anahtarlar, anahtar deposu yüklenene kadar (veya hiç
+	// kullanılmıyorsa) anahtarlığın başlangıç içeriğidir
+	accessKey, err := NewKey("", KeyPurposeAccess, config.SigningAlgorithm, accessMaterial, time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	refreshKey, err := NewKey("", KeyPurposeRefresh, AlgHS256, []byte(config.RefreshTokenSecret), time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	mfaKey, err := NewKey("", KeyPurposeMFA, AlgHS256, mfaSecret(config), time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTManager{
+		config:    config,
+		keyring:   NewKeyring(accessKey, refreshKey, mfaKey),
+		bootstrap: []*Key{accessKey, refreshKey, mfaKey},
+		legacyKeyIDs: map[KeyPurpose]string{
+			KeyPurposeAccess:  accessKey.ID,
+			KeyPurposeRefresh: refreshKey.ID,
+		},
+	}, nil
+}
+
+// mfaSecret returns the configured MFA secret or derives one from the
+// refresh secret, so every instance computes the same key without extra setup
+func mfaSecret(config JWTConfig) []byte {
+	if config.MFATokenSecret != "" {
+		return []byte(config.MFATokenSecret)
+	}
+	if config.RefreshTokenSecret == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, []byte(config.RefreshTokenSecret))
+	mac.Write([]byte("mfa-token"))
+	return mac.Sum(nil)
 }
 
-func NewJWTManager(config JWTConfig) *JWTManager {
-	return &JWTManager{config: config}
+// Keyring returns the keys used to sign and verify tokens
+func (m *JWTManager) Keyring() *Keyring {
+	return m.keyring
 }
 
-func (m *JWTManager) GenerateTokenPair(user *entity.User) (*entity.TokenPair, error) {
+// BootstrapKeys returns the keys built from the static configuration. They
+// seed the key store on first start.
+func (m *JWTManager) BootstrapKeys() []*Key {
+	return m.bootstrap
+}
+
+// Algorithm returns the algorithm new keys of the purpose are generated with.
+// Refresh and MFA tokens are only verified by this service and always use HS256.
+func (m *JWTManager) Algorithm(purpose KeyPurpose) string {
+	if purpose == KeyPurposeAccess {
+		return m.config.SigningAlgorithm
+	}
+	return AlgHS256
+}
+
+// MaxTokenTTL returns the longest lifetime of tokens signed with keys of the
+// purpose, i.e. how long a replaced key must keep verifying
+func (m *JWTManager) MaxTokenTTL(purpose KeyPurpose) time.Duration {
+	switch purpose {
+	case KeyPurposeRefresh:
+		return m.config.RefreshTokenTTL
+	case KeyPurposeMFA:
+		return m.config.MFATokenTTL
+	default:
+		return m.config.AccessTokenTTL
+	}
+}
+
+// GenerateTokenPair creates an access/refresh token pair bound to the given
+// session. An empty scope grants full access.
+func (m *JWTManager) GenerateTokenPair(user *entity.User, sessionID, scope string) (*entity.TokenPair, error) {
 	// Access Token oluştur
-	accessToken, err := m.generateToken(user, entity.AccessToken, m.config.AccessTokenSecret, m.config.AccessTokenTTL)
+	accessToken, err := m.generateToken(user, sessionID, scope, "", entity.AccessToken, m.config.AccessTokenTTL)
 	if err != nil {
 		return nil, fmt.Errorf("access token oluşturulamadı: %w", err)
 	}
 
 	// Refresh Token oluştur
-	refreshToken, err := m.generateToken(user, entity.RefreshToken, m.config.RefreshTokenSecret, m.config.RefreshTokenTTL)
+	refreshToken, err := m.generateToken(user, sessionID, scope, "", entity.RefreshToken, m.config.RefreshTokenTTL)
 	if err != nil {
 		return nil, fmt.Errorf("refresh token oluşturulamadı: %w", err)
 	}
@@ -44,40 +159,129 @@ func (m *JWTManager) GenerateTokenPair(user *entity.User) (*entity.TokenPair, er
 	}, nil
 }
 
-func (m *JWTManager) generateToken(user *entity.User, tokenType entity.TokenType, secret string, ttl time.Duration) (string, error) {
+// GenerateClientTokens creates tokens an OAuth client uses on behalf of the
+// user. Both carry the client id; the refresh token is bound to the session
+// and only issued when withRefresh is set.
+func (m *JWTManager) GenerateClientTokens(user *entity.User, sessionID, clientID, scope string, withRefresh bool) (*entity.TokenPair, error) {
+	accessToken, err := m.generateToken(user, sessionID, scope, clientID, entity.AccessToken, m.config.AccessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("access token oluşturulamadı: %w", err)
+	}
+
+	tokens := &entity.TokenPair{AccessToken: accessToken}
+	if withRefresh {
+		tokens.RefreshToken, err = m.generateToken(user, sessionID, scope, clientID, entity.RefreshToken, m.config.RefreshTokenTTL)
+		if err != nil {
+			return nil, fmt.Errorf("refresh token oluşturulamadı: %w", err)
+		}
+	}
+	return tokens, nil
+}
+
+// GenerateMFAToken creates a short-lived challenge token proving that the
+// password step of a two-factor login succeeded
+func (m *JWTManager) GenerateMFAToken(user *entity.User) (string, error) {
+	token, err := m.generateToken(user, "", "", "", entity.MFAToken, m.config.MFATokenTTL)
+	if err != nil {
+		return "", fmt.Errorf("mfa token oluşturulamadı: %w", err)
+	}
+	return token, nil
+}
+
+// JWKS returns the public keys that verify access tokens, including keys
+// that are not active yet or are being retired. It is empty when tokens are
+// signed with shared secrets. Verifiers must also check the "typ" header
+// (AccessTokenJWTType), "iss" and "aud", since restricted tokens are signed
+// with the same keys.
+func (m *JWTManager) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range m.keyring.Keys() {
+		if key.Purpose != KeyPurposeAccess || !key.IsAsymmetric() || !key.CanVerify(now) {
+			continue
+		}
+		if jwk, err := key.PublicJWK(); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+// AccessTokenTTL returns the lifetime of access tokens
+func (m *JWTManager) AccessTokenTTL() time.Duration {
+	return m.config.AccessTokenTTL
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens and their sessions
+func (m *JWTManager) RefreshTokenTTL() time.Duration {
+	return m.config.RefreshTokenTTL
+}
+
+func (m *JWTManager) generateToken(user *entity.User, sessionID, scope, clientID string, tokenType entity.TokenType, ttl time.Duration) (string, error) {
+	now := time.Now()
+	key := m.keyring.Signing(keyPurpose(tokenType), now)
+	if key == nil {
+		return "", fmt.Errorf("aktif imza anahtarı yok")
+	}
+
+	// Yalnızca API erişimi veren access token'lar (tam yetkili ilk taraf ve
+	// istemci token'ları) aud ve at+jwt taşır; kısıtlı kapsamlı token'lar
+	// JWKS ile doğrulayan servislerde kabul edilmemelidir
+	var audience jwt.ClaimStrings
+	if tokenType == entity.AccessToken && (scope == "" || clientID != "") && m.config.Audience != "" {
+		audience = jwt.ClaimStrings{m.config.Audience}
+	}
+
 	claims := &entity.TokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    m.config.Issuer,
 			Subject:   user.ID,
+			Audience:  audience,
 		},
-		UserID: user.ID,
-		Role:   user.Role,
-		Type:   tokenType,
+		UserID:     user.ID,
+		Role:       user.Role,
+		Type:       tokenType,
+		SessionID:  sessionID,
+		Scope:      scope,
+		ClientID:   clientID,
+		IssuedAtMs: now.UnixMilli(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	token := jwt.NewWithClaims(key.Method(), claims)
+	token.Header["kid"] = key.ID
+	if audience != nil {
+		token.Header["typ"] = AccessTokenJWTType
+	}
+	return token.SignedString(key.signingKey())
 }
 
 func (m *JWTManager) ValidateToken(tokenString string, tokenType entity.TokenType) (*entity.TokenClaims, error) {
-	var secret string
 	switch tokenType {
-	case entity.AccessToken:
-		secret = m.config.AccessTokenSecret
-	case entity.RefreshToken:
-		secret = m.config.RefreshTokenSecret
+	case entity.AccessToken, entity.MFAToken, entity.RefreshToken:
 	default:
 		return nil, fmt.Errorf("geçersiz token tipi")
 	}
+	purpose := keyPurpose(tokenType)
 
 	token, err := jwt.ParseWithClaims(tokenString, &entity.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = m.legacyKeyIDs[purpose]
+		}
+
+		key := m.keyring.Verifying(kid, purpose, time.Now())
+		if key == nil {
+			return nil, fmt.Errorf("bilinmeyen veya emekliye ayrılmış anahtar: %s", kid)
+		}
+		// Yalnızca anahtarın kendi algoritması kabul edilir (algoritma karıştırma saldırılarına karşı)
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("beklenmeyen imza metodu: %v", token.Header["alg"])
 		}
-		return []byte(secret), nil
+		return key.verificationKey(), nil
 	})
 
 	if err != nil {
@@ -93,3 +297,15 @@ func (m *JWTManager) ValidateToken(tokenString string, tokenType entity.TokenTyp
 
 	return nil, fmt.Errorf("geçersiz token")
 }
+
+// keyPurpose maps a token type to the keys that sign it
+func keyPurpose(tokenType entity.TokenType) KeyPurpose {
+	switch tokenType {
+	case entity.RefreshToken:
+		return KeyPurposeRefresh
+	case entity.MFAToken:
+		return KeyPurposeMFA
+	default:
+		return KeyPurposeAccess
+	}
+}
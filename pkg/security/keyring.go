@@ -0,0 +1,72 @@
+package security
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Keyring holds every key that may currently sign or verify tokens. It is
+// safe for concurrent use and can be swapped out as a whole on reload.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys []*Key
+}
+
+func NewKeyring(keys ...*Key) *Keyring {
+	ring := &Keyring{}
+	ring.Replace(keys)
+	return ring
+}
+
+// Replace swaps the whole key set, e.g. after loading it from storage
+func (r *Keyring) Replace(keys []*Key) {
+	sorted := make([]*Key, len(keys))
+	copy(sorted, keys)
+	// Newest activation first so the first usable key is the signing key
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActivatesAt.After(sorted[j].ActivatesAt)
+	})
+
+	r.mu.Lock()
+	r.keys = sorted
+	r.mu.Unlock()
+}
+
+// Keys returns all keys, newest activation first
+func (r *Keyring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, len(r.keys))
+	copy(keys, r.keys)
+	return keys
+}
+
+// Signing returns the most recently activated key that may sign tokens of
+// the given purpose, or nil when there is none
+func (r *Keyring) Signing(purpose KeyPurpose, now time.Time) *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Purpose == purpose && key.CanSign(now) {
+			return key
+		}
+	}
+	return nil
+}
+
+// Verifying returns the key with the given ID if it still verifies tokens
+// of the given purpose
+func (r *Keyring) Verifying(id string, purpose KeyPurpose, now time.Time) *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.ID == id && key.Purpose == purpose && key.CanVerify(now) {
+			return key
+		}
+	}
+	return nil
+}
@@ -0,0 +1,380 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// KeyPurpose tells which kind of token a key signs. Only access keys are
+// published; refresh and MFA challenge tokens are verified by this service
+// alone and use their own HS256 keys.
+type KeyPurpose string
+
+const (
+	KeyPurposeAccess  KeyPurpose = "access"
+	KeyPurposeRefresh KeyPurpose = "refresh"
+	KeyPurposeMFA     KeyPurpose = "mfa"
+)
+
+// Key is a single signing key of the keyring. It signs new tokens from
+// ActivatesAt on and verifies tokens until RetiresAt.
+type Key struct {
+	ID          string
+	Purpose     KeyPurpose
+	Algorithm   string
+	ActivatesAt time.Time
+	RetiresAt   *time.Time
+
+	material []byte
+	secret   []byte
+	private  crypto.Signer
+}
+
+// JWK is the public part of a signing key in RFC 7517 format. NotBefore and
+// Expires are additional members announcing when the key starts signing and
+// when it stops verifying, so consumers can see the rotation overlap.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Expires   int64  `json:"exp,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKey builds a key from its stored material: the raw secret for HS256 or
+// a PEM encoded private key (PKCS#8, PKCS#1 or SEC 1) otherwise. An empty id
+// is derived from the material so every instance computes the same one.
+func NewKey(id string, purpose KeyPurpose, algorithm string, material []byte, activatesAt time.Time, retiresAt *time.Time) (*Key, error) {
+	key := &Key{
+		ID:          id,
+		Purpose:     purpose,
+		Algorithm:   algorithm,
+		ActivatesAt: activatesAt,
+		RetiresAt:   retiresAt,
+		material:    material,
+	}
+
+	if algorithm == AlgHS256 {
+		if len(material) == 0 {
+			return nil, fmt.Errorf("%s anahtarı için secret boş", purpose)
+		}
+		key.secret = material
+		if key.ID == "" {
+			sum := sha256.Sum256(append([]byte("kid:"), material...))
+			key.ID = base64.RawURLEncoding.EncodeToString(sum[:16])
+		}
+		return key, nil
+	}
+
+	private, err := parsePrivateKey(algorithm, material)
+	if err != nil {
+		return nil, err
+	}
+	key.private = private
+	if key.ID == "" {
+		jwk, err := publicJWK(algorithm, "", private.Public())
+		if err != nil {
+			return nil, err
+		}
+		key.ID = jwkThumbprint(jwk)
+	}
+	return key, nil
+}
+
+// GenerateKeyMaterial creates fresh key material for the algorithm in the
+// format NewKey expects
+func GenerateKeyMaterial(algorithm string) ([]byte, error) {
+	var private interface{}
+	var err error
+	switch algorithm {
+	case AlgHS256:
+		secret := make([]byte, 64)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("desteklenmeyen imza algoritması: %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Material returns the stored form of the key
+func (k *Key) Material() []byte {
+	return k.material
+}
+
+// Method returns the JWT signing method for the key
+func (k *Key) Method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// IsAsymmetric reports whether the key has a publishable public part
+func (k *Key) IsAsymmetric() bool {
+	return k.private != nil
+}
+
+// CanSign reports whether the key may sign new tokens at the given time
+func (k *Key) CanSign(now time.Time) bool {
+	return !k.ActivatesAt.After(now) && k.CanVerify(now)
+}
+
+// CanVerify reports whether tokens signed with the key are still accepted
+func (k *Key) CanVerify(now time.Time) bool {
+	return k.RetiresAt == nil || now.Before(*k.RetiresAt)
+}
+
+func (k *Key) signingKey() interface{} {
+	if k.private != nil {
+		return k.private
+	}
+	return k.secret
+}
+
+func (k *Key) verificationKey() interface{} {
+	if k.private != nil {
+		return k.private.Public()
+	}
+	return k.secret
+}
+
+// PublicJWK returns the public key in JWK form with its activation window
+func (k *Key) PublicJWK() (JWK, error) {
+	if k.private == nil {
+		return JWK{}, fmt.Errorf("simetrik anahtar yayınlanamaz")
+	}
+	jwk, err := publicJWK(k.Algorithm, k.ID, k.private.Public())
+	if err != nil {
+		return JWK{}, err
+	}
+	jwk.NotBefore = k.ActivatesAt.Unix()
+	if k.RetiresAt != nil {
+		jwk.Expires = k.RetiresAt.Unix()
+	}
+	return jwk, nil
+}
+
+func parsePrivateKey(algorithm string, data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("PEM bloğu bulunamadı")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imza anahtarı ayrıştırılamadı: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgRS256 {
+			return nil, fmt.Errorf("RSA anahtarı %s ile kullanılamaz", algorithm)
+		}
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA anahtarı en az 2048 bit olmalı")
+		}
+	case *ecdsa.PrivateKey:
+		if algorithm != AlgES256 {
+			return nil, fmt.Errorf("EC anahtarı %s ile kullanılamaz", algorithm)
+		}
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 için P-256 eğrisi gerekli")
+		}
+	case ed25519.PrivateKey:
+		if algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("Ed25519 anahtarı %s ile kullanılamaz", algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("desteklenmeyen anahtar tipi: %T", key)
+	}
+
+	return key.(crypto.Signer), nil
+}
+
+func publicJWK(algorithm, keyID string, public crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Use: "sig", Algorithm: algorithm, KeyID: keyID}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("desteklenmeyen açık anahtar tipi: %T", public)
+	}
+
+	return jwk, nil
+}
+
+// PublicKey decodes the public key carried by a JWK published by another
+// issuer. RSA, EC (P-256, P-384, P-521) and Ed25519 keys are supported.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := func(field, value string) ([]byte, error) {
+		raw, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(raw) == 0 {
+			return nil, fmt.Errorf("JWK %q alanı geçersiz", field)
+		}
+		return raw, nil
+	}
+
+	switch k.KeyType {
+	case "RSA":
+		n, err := decode("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("JWK RSA üssü geçersiz")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("desteklenmeyen JWK eğrisi: %s", k.Curve)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("JWK noktası eğri üzerinde değil")
+		}
+		return pub, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("desteklenmeyen JWK eğrisi: %s", k.Curve)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("JWK Ed25519 anahtarı geçersiz")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("desteklenmeyen JWK tipi: %s", k.KeyType)
+	}
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint from the required members
+// of the key in lexicographic order
+func jwkThumbprint(jwk JWK) string {
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
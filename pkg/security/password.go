@@ -1,24 +1,189 @@
 package security
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Supported password hashing algorithms
 const (
-	hashCost = 12
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
 )
 
-// HashPassword creates a bcrypt hash of the password
-func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), hashCost)
-	if err != nil {
+var ErrUnknownHashFormat = errors.New("tanınmayan şifre hash biçimi")
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the OWASP recommendation for argon2id
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// PasswordHashConfig selects the algorithm and parameters new hashes are
+// created with. Hashes of any supported algorithm can still be verified.
+type PasswordHashConfig struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+// PasswordHasher creates and verifies password hashes. Argon2id hashes are
+// stored in PHC string format ($argon2id$v=19$m=..,t=..,p=..$salt$hash),
+// bcrypt hashes in their modular crypt format ($2a$..).
+type PasswordHasher struct {
+	config PasswordHashConfig
+}
+
+func NewPasswordHasher(config PasswordHashConfig) (*PasswordHasher, error) {
+	switch config.Algorithm {
+	case "":
+		config.Algorithm = HashArgon2id
+	case HashArgon2id, HashBcrypt:
+	default:
+		return nil, fmt.Errorf("desteklenmeyen şifre hash algoritması: %s", config.Algorithm)
+	}
+
+	if config.Argon2.Memory == 0 {
+		config.Argon2.Memory = DefaultArgon2Params.Memory
+	}
+	if config.Argon2.Iterations == 0 {
+		config.Argon2.Iterations = DefaultArgon2Params.Iterations
+	}
+	if config.Argon2.Parallelism == 0 {
+		config.Argon2.Parallelism = DefaultArgon2Params.Parallelism
+	}
+	if config.Argon2.SaltLength == 0 {
+		config.Argon2.SaltLength = DefaultArgon2Params.SaltLength
+	}
+	if config.Argon2.KeyLength == 0 {
+		config.Argon2.KeyLength = DefaultArgon2Params.KeyLength
+	}
+	if config.BcryptCost == 0 {
+		config.BcryptCost = 12
+	}
+	if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("geçersiz bcrypt maliyeti: %d", config.BcryptCost)
+	}
+
+	return &PasswordHasher{config: config}, nil
+}
+
+// Hash hashes the password with the configured algorithm
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == HashBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashed), nil
+	}
+
+	params := h.config.Argon2
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(hashedBytes), nil
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares a password with a hash of any supported algorithm
+func (h *PasswordHasher) Verify(password, encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		return subtle.ConstantTimeCompare(candidate, key) == 1
+	case isBcryptHash(encoded):
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+	default:
+		return false
+	}
+}
+
+// NeedsRehash reports whether the hash was created with another algorithm or
+// with parameters different from the current configuration
+func (h *PasswordHasher) NeedsRehash(encoded string) bool {
+	switch h.config.Algorithm {
+	case HashArgon2id:
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return true
+		}
+		current := h.config.Argon2
+		return params.Memory != current.Memory ||
+			params.Iterations != current.Iterations ||
+			params.Parallelism != current.Parallelism ||
+			uint32(len(salt)) != current.SaltLength ||
+			uint32(len(key)) != current.KeyLength
+	case HashBcrypt:
+		if !isBcryptHash(encoded) {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != h.config.BcryptCost
+	}
+	return false
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
 }
 
-// CheckPassword compares a password with a hash
-func CheckPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// decodeArgon2id parses a PHC string created by Hash
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != HashArgon2id {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }